@@ -78,13 +78,8 @@ func (s *docService) GetDocs(ctx context.Context, bookID any, request *GetDocsRe
 		return nil, resp, err
 	}
 
-	var total int
-	if meta := resp.meta(); meta != nil {
-		total = meta.Total
-	}
-
 	return &GetDocsResponse{
-		Total: total,
+		Total: resp.Total(),
 		Docs:  docs,
 	}, resp, nil
 }
@@ -115,7 +110,7 @@ type GetDocsResponse struct {
 
 // CreateDoc 创建文档
 //
-// 注意: 创建文档后不会自动添加到目录，需要调用"知识库目录更新接口"更新到目录中
+// 注意: 创建文档后不会自动添加到目录，需要调用 UpdateTOC 更新到目录中
 func (s *docService) CreateDoc(ctx context.Context, bookID any, request *CreateDocRequest, opts ...RequestOption) (*Doc, *Response, error) {
 	bid, err := parseID(bookID)
 	if err != nil {
@@ -173,11 +168,139 @@ type CreateDocRequest struct {
 	Body   *string     `json:"body,omitempty"`   // 正文内容
 }
 
-// 获取文档详情
-// 更新文档
-// 删除文档
-// 获取文档历史版本列表
-// 获取文档历史版本详情
+// UpdateDoc 更新文档
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+// docID: 文档 ID 或 slug
+func (s *docService) UpdateDoc(ctx context.Context, bookID, docID any, request *UpdateDocRequest, opts ...RequestOption) (*Doc, *Response, error) {
+	bid, err := parseID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	did, err := parseID(docID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, fmt.Sprintf("repos/%s/docs/%s", bid, did), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc Doc
+	resp, err := s.client.Do(req, &doc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &doc, resp, nil
+}
+
+type UpdateDocRequest struct {
+	Slug   *string     `json:"slug,omitempty"`   // 路径
+	Title  *string     `json:"title,omitempty"`  // 标题
+	Public *AccessType `json:"public,omitempty"` // 公开性 (0:私密, 1:公开, 2:企业内公开)
+	Format *DocFormat  `json:"format,omitempty"` // 内容格式 (markdown:Markdown 格式, html:HTML 标准格式, lake:语雀 Lake 格式)
+	Body   *string     `json:"body,omitempty"`   // 正文内容
+}
+
+// DeleteDoc 删除文档
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+// docID: 文档 ID 或 slug
+func (s *docService) DeleteDoc(ctx context.Context, bookID, docID any, opts ...RequestOption) (*Doc, *Response, error) {
+	bid, err := parseID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	did, err := parseID(docID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, fmt.Sprintf("repos/%s/docs/%s", bid, did), nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc Doc
+	resp, err := s.client.Do(req, &doc)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &doc, resp, nil
+}
+
+type DocVersion struct {
+	ID        int       `json:"id,omitempty"`
+	DocID     int       `json:"doc_id,omitempty"`
+	Slug      string    `json:"slug,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	UserID    int       `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	User      *User     `json:"user,omitempty"` // 版本作者
+
+	// 以下字段是获取文档历史版本详情时才有的字段
+	Format   *DocFormat `json:"format,omitempty"`
+	Body     *string    `json:"body,omitempty"`
+	BodyHTML *string    `json:"body_html,omitempty"`
+	BodyASL  *string    `json:"body_asl,omitempty"`
+	Diff     *string    `json:"diff,omitempty"`
+}
+
+// ListDocVersions 获取文档历史版本列表
+//
+// docID: 文档 ID
+func (s *docService) ListDocVersions(ctx context.Context, docID any, opts ...RequestOption) ([]*DocVersion, *Response, error) {
+	did, err := parseID(docID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, "doc_versions", &listDocVersionsRequest{DocID: did}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var versions []*DocVersion
+	resp, err := s.client.Do(req, &versions)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return versions, resp, nil
+}
+
+type listDocVersionsRequest struct {
+	DocID string `url:"doc_id"` // 文档 ID
+}
+
+// GetDocVersion 获取文档历史版本详情
+//
+// versionID: 版本 ID，如 Doc.LatestVersionID
+func (s *docService) GetDocVersion(ctx context.Context, versionID any, opts ...RequestOption) (*DocVersion, *Response, error) {
+	vid, err := parseID(versionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("doc_versions/%s", vid), nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var version DocVersion
+	resp, err := s.client.Do(req, &version)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &version, resp, nil
+}
 
 // GetTOCs 获取目录
 func (s *docService) GetTOCs(ctx context.Context, bookID any, opts ...RequestOption) ([]*TOC, *Response, error) {
@@ -197,6 +320,10 @@ func (s *docService) GetTOCs(ctx context.Context, bookID any, opts ...RequestOpt
 		return nil, resp, err
 	}
 
+	return rawTocsToTOCs(rawTocs), resp, nil
+}
+
+func rawTocsToTOCs(rawTocs []*rawTOC) []*TOC {
 	tocs := make([]*TOC, len(rawTocs))
 	for i, rawTOC := range rawTocs {
 		toc := rawTOC.TOC
@@ -208,7 +335,7 @@ func (s *docService) GetTOCs(ctx context.Context, bookID any, opts ...RequestOpt
 		tocs[i] = &toc
 	}
 
-	return tocs, resp, nil
+	return tocs
 }
 
 type rawTOC struct {
@@ -256,4 +383,61 @@ type TOC struct {
 	ParentUUID  string  `json:"parent_uuid,omitempty"`  // 父级节点 uuid
 }
 
-// 更新目录
+// UpdateTOC 更新目录
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+//
+// 返回更新后的完整目录
+func (s *docService) UpdateTOC(ctx context.Context, bookID any, request *UpdateTOCRequest, opts ...RequestOption) ([]*TOC, *Response, error) {
+	bid, err := parseID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, fmt.Sprintf("repos/%s/toc", bid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rawTocs []*rawTOC
+	resp, err := s.client.Do(req, &rawTocs)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return rawTocsToTOCs(rawTocs), resp, nil
+}
+
+// TOCAction 目录操作 (appendNode:尾插, prependNode:头插, editNode:编辑节点, removeNode:删除节点)
+type TOCAction string
+
+const (
+	TOCActionAppendNode  TOCAction = "appendNode"  // 尾插
+	TOCActionPrependNode TOCAction = "prependNode" // 头插
+	TOCActionEditNode    TOCAction = "editNode"    // 编辑节点
+	TOCActionRemoveNode  TOCAction = "removeNode"  // 删除节点
+)
+
+// TOCActionMode 目录操作模式 (sibling:同级, child:子级)
+//
+// 对于 appendNode/prependNode 表示插入到目标节点的同级或子级;
+// 对于 removeNode 表示仅删除节点本身(子节点上移)或连同子节点一起删除
+type TOCActionMode string
+
+const (
+	TOCActionModeSibling TOCActionMode = "sibling" // 同级
+	TOCActionModeChild   TOCActionMode = "child"   // 子级
+)
+
+type UpdateTOCRequest struct {
+	Action     *TOCAction     `json:"action,omitempty"`      // 操作
+	ActionMode *TOCActionMode `json:"action_mode,omitempty"` // 操作模式
+	TargetUUID *string        `json:"target_uuid,omitempty"` // 目标节点 UUID, 不填默认为根节点
+	NodeUUID   *string        `json:"node_uuid,omitempty"`   // 操作节点 UUID [移动节点/编辑节点/删除节点时必填]
+	DocIDs     []int          `json:"doc_ids,omitempty"`     // 文档 ID 列表 [添加文档节点时必填]
+	Type       *TOCType       `json:"type,omitempty"`        // 节点类型 (DOC:文档, LINK:外链, TITLE:分组) [添加节点时必填]
+	Title      *string        `json:"title,omitempty"`       // 节点名称 [添加外链/分组节点时必填]
+	URL        *string        `json:"url,omitempty"`         // 节点 URL [添加外链节点时必填]
+	OpenWindow *int           `json:"open_window,omitempty"` // 是否在新窗口打开 (0:当前页打开, 1:新窗口打开)
+	Visible    *int           `json:"visible,omitempty"`     // 是否可见 (0:不可见, 1:可见)
+}
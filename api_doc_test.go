@@ -185,3 +185,142 @@ func TestDocService_GetDoc(t *testing.T) {
 	assert.Equal(t, mustParseTime(t, "2025-01-02T01:29:30.000Z"), doc.CreatedAt)
 	assert.Equal(t, mustParseTime(t, "2025-02-08T03:26:48.000Z"), doc.UpdatedAt)
 }
+
+func TestDocService_UpdateDoc(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/repos/org/book/docs/string", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{
+			"slug":   "handbook",
+			"title":  "工程手册",
+			"public": float64(AccessTypePublic),
+			"body":   "# 工程手册",
+		}, req)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/update_doc.json"))
+	}))
+
+	doc, _, err := client.DocService.UpdateDoc(ctx, "org/book", "string", &UpdateDocRequest{
+		Slug:   new("handbook"),
+		Title:  new("工程手册"),
+		Public: new(AccessTypePublic),
+		Body:   new("# 工程手册"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 20751111, doc.ID)
+	assert.Equal(t, "handbook", doc.Slug)
+	assert.Equal(t, AccessTypePublic, doc.Public)
+	require.NotNil(t, doc.Body)
+	assert.Equal(t, "# 工程手册", *doc.Body)
+	assert.Equal(t, 903333, doc.LatestVersionID)
+	assert.Equal(t, mustParseTime(t, "2025-02-26T08:12:30.000Z"), doc.ContentUpdatedAt)
+}
+
+func TestDocService_DeleteDoc(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/repos/1292222/docs/20751111", r.URL.Path)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/delete_doc.json"))
+	}))
+
+	doc, _, err := client.DocService.DeleteDoc(ctx, 1292222, 20751111)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20751111, doc.ID)
+	assert.Equal(t, "handbook", doc.Slug)
+	assert.Nil(t, doc.Body)
+}
+
+func TestDocService_ListDocVersions(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/doc_versions", r.URL.Path)
+		assert.Equal(t, "20751111", r.URL.Query().Get("doc_id"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/list_doc_versions.json"))
+	}))
+
+	versions, _, err := client.DocService.ListDocVersions(ctx, 20751111)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	assert.Equal(t, 903333, versions[0].ID)
+	assert.Equal(t, 20751111, versions[0].DocID)
+	require.NotNil(t, versions[0].User)
+	assert.Equal(t, "王五", versions[0].User.Name)
+	assert.Nil(t, versions[0].Body)
+	assert.Equal(t, mustParseTime(t, "2025-02-25T13:40:06.000Z"), versions[1].CreatedAt)
+}
+
+func TestDocService_GetDocVersion(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/doc_versions/903333", r.URL.Path)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/get_doc_version.json"))
+	}))
+
+	version, _, err := client.DocService.GetDocVersion(ctx, 903333)
+	require.NoError(t, err)
+
+	assert.Equal(t, 903333, version.ID)
+	assert.Equal(t, "工程手册", version.Title)
+	require.NotNil(t, version.Format)
+	assert.Equal(t, DocFormatMarkdown, *version.Format)
+	require.NotNil(t, version.Body)
+	assert.Equal(t, "# 工程手册", *version.Body)
+	require.NotNil(t, version.BodyHTML)
+	assert.Equal(t, "<h1>工程手册</h1>\n", *version.BodyHTML)
+	require.NotNil(t, version.User)
+	assert.Equal(t, 12222, version.User.ID)
+	assert.Equal(t, mustParseTime(t, "2025-02-26T08:12:29.000Z"), version.CreatedAt)
+}
+
+func TestDocService_UpdateTOC(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/repos/org/book/toc", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{
+			"action":      "appendNode",
+			"action_mode": "child",
+			"target_uuid": "5wNEEZX3KK_hwec1",
+			"doc_ids":     []any{float64(20751111)},
+			"type":        "DOC",
+		}, req)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/update_toc.json"))
+	}))
+
+	tocs, _, err := client.DocService.UpdateTOC(ctx, "org/book", &UpdateTOCRequest{
+		Action:     new(TOCActionAppendNode),
+		ActionMode: new(TOCActionModeChild),
+		TargetUUID: new("5wNEEZX3KK_hwec1"),
+		DocIDs:     []int{20751111},
+		Type:       new(TOCTypeDoc),
+	})
+	require.NoError(t, err)
+	require.Len(t, tocs, 2)
+
+	assert.Equal(t, "c2Ke3oBq7tbWyl_N", tocs[0].ChildUUID)
+	assert.Equal(t, &TOC{
+		UUID:       "c2Ke3oBq7tbWyl_N",
+		Type:       TOCTypeDoc,
+		Title:      "工程手册",
+		URL:        "handbook",
+		Slug:       "handbook",
+		ID:         20751111,
+		DocID:      20751111,
+		Level:      1,
+		Depth:      2,
+		Visible:    1,
+		ParentUUID: "5wNEEZX3KK_hwec1",
+	}, tocs[1])
+}
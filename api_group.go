@@ -0,0 +1,175 @@
+package yuque
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type groupService struct {
+	client *Client
+}
+
+type Group struct {
+	ID               int        `json:"id,omitempty"`
+	Type             string     `json:"type,omitempty"`
+	Login            string     `json:"login,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	AvatarURL        string     `json:"avatar_url,omitempty"`
+	BooksCount       int        `json:"books_count,omitempty"`
+	PublicBooksCount int        `json:"public_books_count,omitempty"`
+	MembersCount     int        `json:"members_count,omitempty"`
+	Public           AccessType `json:"public,omitempty"`
+	Description      string     `json:"description,omitempty"`
+	CreatedAt        time.Time  `json:"created_at,omitzero"`
+	UpdatedAt        time.Time  `json:"updated_at,omitzero"`
+}
+
+// GroupMemberRole 团队成员角色 (0:管理员, 1:成员, 2:只读成员)
+type GroupMemberRole int
+
+const (
+	GroupMemberRoleAdmin    GroupMemberRole = 0 // 管理员
+	GroupMemberRoleMember   GroupMemberRole = 1 // 成员
+	GroupMemberRoleReadOnly GroupMemberRole = 2 // 只读成员
+)
+
+type GroupMember struct {
+	ID        int             `json:"id,omitempty"`
+	GroupID   int             `json:"group_id,omitempty"`
+	UserID    int             `json:"user_id,omitempty"`
+	Role      GroupMemberRole `json:"role"`
+	CreatedAt time.Time       `json:"created_at,omitzero"`
+	UpdatedAt time.Time       `json:"updated_at,omitzero"`
+	Group     *Group          `json:"group,omitempty"`
+	User      *User           `json:"user,omitempty"`
+}
+
+// GetUserGroups 获取用户的团队
+//
+// login: 用户 ID 或 登录名(login)
+func (s *groupService) GetUserGroups(ctx context.Context, login any, request *GetUserGroupsRequest, opts ...RequestOption) ([]*Group, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("users/%s/groups", lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var groups []*Group
+	resp, err := s.client.Do(req, &groups)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return groups, resp, nil
+}
+
+type GetUserGroupsRequest struct {
+	// 角色 (0:管理员, 1:成员)
+	Role *GroupMemberRole `url:"role,omitempty"`
+
+	// 偏移量 [分页参数]
+	Offset *int `url:"offset,omitempty"`
+}
+
+// GetGroupMembers 获取团队的成员
+//
+// login: 团队 ID 或 登录名(login)
+func (s *groupService) GetGroupMembers(ctx context.Context, login any, request *GetGroupMembersRequest, opts ...RequestOption) ([]*GroupMember, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("groups/%s/users", lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var members []*GroupMember
+	resp, err := s.client.Do(req, &members)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return members, resp, nil
+}
+
+type GetGroupMembersRequest struct {
+	// 角色 (0:管理员, 1:成员, 2:只读成员)
+	Role *GroupMemberRole `url:"role,omitempty"`
+
+	// 偏移量 [分页参数]
+	Offset *int `url:"offset,omitempty"`
+}
+
+// UpdateGroupMember 变更成员
+//
+// login: 团队 ID 或 登录名(login)
+// userID: 用户 ID 或 登录名(login)
+func (s *groupService) UpdateGroupMember(ctx context.Context, login, userID any, request *UpdateGroupMemberRequest, opts ...RequestOption) (*GroupMember, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uid, err := parseID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, fmt.Sprintf("groups/%s/users/%s", lid, uid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var member GroupMember
+	resp, err := s.client.Do(req, &member)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &member, resp, nil
+}
+
+type UpdateGroupMemberRequest struct {
+	Role *GroupMemberRole `json:"role,omitempty"` // 角色 (0:管理员, 1:成员, 2:只读成员)
+}
+
+// DeleteGroupMember 删除成员
+//
+// login: 团队 ID 或 登录名(login)
+// userID: 用户 ID 或 登录名(login)
+func (s *groupService) DeleteGroupMember(ctx context.Context, login, userID any, opts ...RequestOption) (*DeleteGroupMemberResponse, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uid, err := parseID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, fmt.Sprintf("groups/%s/users/%s", lid, uid), nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var response DeleteGroupMemberResponse
+	resp, err := s.client.Do(req, &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &response, resp, nil
+}
+
+type DeleteGroupMemberResponse struct {
+	UserID int `json:"user_id,omitempty"`
+}
@@ -0,0 +1,105 @@
+package yuque
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupService_GetUserGroups(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/users/zzzzzz/groups", r.URL.Path)
+		assert.Equal(t, "0", r.URL.Query().Get("role"))
+		assert.Equal(t, "20", r.URL.Query().Get("offset"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/group/get_user_groups.json"))
+	}))
+
+	groups, _, err := client.GroupService.GetUserGroups(ctx, "zzzzzz", &GetUserGroupsRequest{
+		Role:   new(GroupMemberRoleAdmin),
+		Offset: new(20),
+	})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, &Group{
+		ID:               1781111,
+		Type:             "Group",
+		Login:            "yuque",
+		Name:             "yuque group",
+		AvatarURL:        "https://cdn.nlark.com/yuque/0/2022/png/95500/1111-avatar/81111.png",
+		BooksCount:       28,
+		PublicBooksCount: 1,
+		MembersCount:     26,
+		Public:           AccessTypePrivate,
+		Description:      "沉淀新人手册",
+		CreatedAt:        time.Date(2020, 7, 16, 3, 1, 36, 0, time.UTC),
+		UpdatedAt:        time.Date(2025, 2, 10, 8, 19, 55, 0, time.UTC),
+	}, groups[0])
+	assert.Equal(t, "infra", groups[1].Login)
+}
+
+func TestGroupService_GetGroupMembers(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/groups/yuque/users", r.URL.Path)
+		assert.Empty(t, r.URL.RawQuery)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/group/get_group_members.json"))
+	}))
+
+	members, _, err := client.GroupService.GetGroupMembers(ctx, "yuque", nil)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	assert.Equal(t, 181111, members[0].UserID)
+	assert.Equal(t, GroupMemberRoleAdmin, members[0].Role)
+	require.NotNil(t, members[0].User)
+	assert.Equal(t, "张三", members[0].User.Name)
+
+	assert.Equal(t, GroupMemberRoleReadOnly, members[1].Role)
+	assert.Equal(t, 1781111, members[1].GroupID)
+}
+
+func TestGroupService_UpdateGroupMember(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/groups/yuque/users/fffff", r.URL.Path)
+
+		var req UpdateGroupMemberRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotNil(t, req.Role)
+		assert.Equal(t, GroupMemberRoleMember, *req.Role)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/group/update_group_member.json"))
+	}))
+
+	member, _, err := client.GroupService.UpdateGroupMember(ctx, "yuque", "fffff", &UpdateGroupMemberRequest{
+		Role: new(GroupMemberRoleMember),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 9111, member.UserID)
+	assert.Equal(t, GroupMemberRoleMember, member.Role)
+	require.NotNil(t, member.Group)
+	assert.Equal(t, "yuque", member.Group.Login)
+	assert.Nil(t, member.User)
+}
+
+func TestGroupService_DeleteGroupMember(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/groups/1781111/users/9111", r.URL.Path)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/group/delete_group_member.json"))
+	}))
+
+	resp, _, err := client.GroupService.DeleteGroupMember(ctx, 1781111, 9111)
+	require.NoError(t, err)
+	assert.Equal(t, &DeleteGroupMemberResponse{UserID: 9111}, resp)
+}
@@ -1,6 +1,15 @@
 package yuque
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type repoService struct {
+	client *Client
+}
 
 type Book struct {
 	ID               int        `json:"id"`
@@ -21,3 +30,175 @@ type Book struct {
 	Namespace        string     `json:"namespace"`
 	User             *User      `json:"user"`
 }
+
+// GetUserRepos 获取用户的知识库列表
+//
+// login: 用户 ID 或 登录名(login)
+func (s *repoService) GetUserRepos(ctx context.Context, login any, request *GetReposRequest, opts ...RequestOption) (*GetReposResponse, *Response, error) {
+	return s.getRepos(ctx, "users", login, request, opts)
+}
+
+// GetGroupRepos 获取团队的知识库列表
+//
+// login: 团队 ID 或 登录名(login)
+func (s *repoService) GetGroupRepos(ctx context.Context, login any, request *GetReposRequest, opts ...RequestOption) (*GetReposResponse, *Response, error) {
+	return s.getRepos(ctx, "groups", login, request, opts)
+}
+
+func (s *repoService) getRepos(ctx context.Context, owner string, login any, request *GetReposRequest, opts []RequestOption) (*GetReposResponse, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("%s/%s/repos", owner, lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var books []*Book
+	resp, err := s.client.Do(req, &books)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &GetReposResponse{
+		Total: resp.Total(),
+		Books: books,
+	}, resp, nil
+}
+
+type GetReposRequest struct {
+	// 偏移量 [分页参数]
+	Offset *int `url:"offset,omitempty"`
+
+	// 每页数量 [分页参数]
+	Limit *int `url:"limit,omitempty"`
+
+	// 知识库类型 (Book:文档, Design:图集)
+	Type *BookType `url:"type,omitempty"`
+}
+
+type GetReposResponse struct {
+	Total int     `json:"total,omitempty"`
+	Books []*Book `json:"books,omitempty"`
+}
+
+// CreateUserRepo 创建用户的知识库
+//
+// login: 用户 ID 或 登录名(login)
+func (s *repoService) CreateUserRepo(ctx context.Context, login any, request *CreateRepoRequest, opts ...RequestOption) (*Book, *Response, error) {
+	return s.createRepo(ctx, "users", login, request, opts)
+}
+
+// CreateGroupRepo 创建团队的知识库
+//
+// login: 团队 ID 或 登录名(login)
+func (s *repoService) CreateGroupRepo(ctx context.Context, login any, request *CreateRepoRequest, opts ...RequestOption) (*Book, *Response, error) {
+	return s.createRepo(ctx, "groups", login, request, opts)
+}
+
+func (s *repoService) createRepo(ctx context.Context, owner string, login any, request *CreateRepoRequest, opts []RequestOption) (*Book, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, fmt.Sprintf("%s/%s/repos", owner, lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var book Book
+	resp, err := s.client.Do(req, &book)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &book, resp, nil
+}
+
+type CreateRepoRequest struct {
+	Name            *string     `json:"name,omitempty"`            // 名称
+	Slug            *string     `json:"slug,omitempty"`            // 路径
+	Description     *string     `json:"description,omitempty"`     // 简介
+	Public          *AccessType `json:"public,omitempty"`          // 公开性 (0:私密, 1:公开, 2:企业内公开)
+	EnhancedPrivacy *bool       `json:"enhancedPrivacy,omitempty"` // 增强私密性
+}
+
+// GetRepo 获取知识库详情
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+func (s *repoService) GetRepo(ctx context.Context, bookID any, opts ...RequestOption) (*Book, *Response, error) {
+	bid, err := parseID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("repos/%s", bid), nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var book Book
+	resp, err := s.client.Do(req, &book)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &book, resp, nil
+}
+
+// UpdateRepo 更新知识库
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+func (s *repoService) UpdateRepo(ctx context.Context, bookID any, request *UpdateRepoRequest, opts ...RequestOption) (*Book, *Response, error) {
+	bid, err := parseID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, fmt.Sprintf("repos/%s", bid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var book Book
+	resp, err := s.client.Do(req, &book)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &book, resp, nil
+}
+
+type UpdateRepoRequest struct {
+	Name        *string     `json:"name,omitempty"`        // 名称
+	Slug        *string     `json:"slug,omitempty"`        // 路径
+	Description *string     `json:"description,omitempty"` // 简介
+	Public      *AccessType `json:"public,omitempty"`      // 公开性 (0:私密, 1:公开, 2:企业内公开)
+	TOC         *string     `json:"toc,omitempty"`         // 目录 (Markdown 格式)
+}
+
+// DeleteRepo 删除知识库
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+func (s *repoService) DeleteRepo(ctx context.Context, bookID any, opts ...RequestOption) (*Book, *Response, error) {
+	bid, err := parseID(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, fmt.Sprintf("repos/%s", bid), nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var book Book
+	resp, err := s.client.Do(req, &book)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &book, resp, nil
+}
@@ -0,0 +1,157 @@
+package yuque
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoService_GetUserRepos(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/users/yuque/repos", r.URL.Path)
+		assert.Equal(t, "0", r.URL.Query().Get("offset"))
+		assert.Equal(t, "20", r.URL.Query().Get("limit"))
+		assert.Equal(t, "Book", r.URL.Query().Get("type"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/get_repos.json"))
+	}))
+
+	resp, _, err := client.RepoService.GetUserRepos(ctx, "yuque", &GetReposRequest{
+		Offset: new(0),
+		Limit:  new(20),
+		Type:   new(BookTypeBook),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 28, resp.Total)
+	require.Len(t, resp.Books, 2)
+	assert.Equal(t, 1292222, resp.Books[0].ID)
+	assert.Equal(t, "yuque/guide", resp.Books[0].Namespace)
+	require.NotNil(t, resp.Books[0].User)
+	assert.Equal(t, "yuque", resp.Books[0].User.Login)
+	assert.Equal(t, BookTypeDesign, resp.Books[1].Type)
+	assert.Equal(t, AccessTypeInner, resp.Books[1].Public)
+}
+
+func TestRepoService_GetGroupRepos(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/groups/1781111/repos", r.URL.Path)
+		assert.Empty(t, r.URL.RawQuery)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/get_repos.json"))
+	}))
+
+	resp, _, err := client.RepoService.GetGroupRepos(ctx, 1781111, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 28, resp.Total)
+	assert.Len(t, resp.Books, 2)
+}
+
+func TestRepoService_CreateRepo(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		create func(client *Client, request *CreateRepoRequest) (*Book, *Response, error)
+	}{
+		{"user", "/users/yuque/repos", func(client *Client, request *CreateRepoRequest) (*Book, *Response, error) {
+			return client.RepoService.CreateUserRepo(ctx, "yuque", request)
+		}},
+		{"group", "/groups/yuque/repos", func(client *Client, request *CreateRepoRequest) (*Book, *Response, error) {
+			return client.RepoService.CreateGroupRepo(ctx, "yuque", request)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, tt.path, r.URL.Path)
+
+				var req CreateRepoRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, "工程手册", *req.Name)
+				assert.Equal(t, "handbook", *req.Slug)
+				assert.Equal(t, "研发规范", *req.Description)
+				assert.Equal(t, AccessTypePrivate, *req.Public)
+				assert.True(t, *req.EnhancedPrivacy)
+
+				_, _ = w.Write(loadData(t, "internal/testdata/api/repo/create_repo.json"))
+			}))
+
+			book, _, err := tt.create(client, &CreateRepoRequest{
+				Name:            new("工程手册"),
+				Slug:            new("handbook"),
+				Description:     new("研发规范"),
+				Public:          new(AccessTypePrivate),
+				EnhancedPrivacy: new(true),
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, 1294444, book.ID)
+			assert.Equal(t, "yuque/handbook", book.Namespace)
+			assert.Equal(t, mustParseTime(t, "2025-03-01T02:00:00.000Z"), book.CreatedAt)
+		})
+	}
+}
+
+func TestRepoService_GetRepo(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/repos/yuque/guide", r.URL.Path)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/get_repo.json"))
+	}))
+
+	book, _, err := client.RepoService.GetRepo(ctx, "yuque/guide")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1292222, book.ID)
+	assert.Equal(t, BookTypeBook, book.Type)
+	assert.Equal(t, "guide", book.Slug)
+	assert.Equal(t, "新人指南", book.Name)
+	assert.Equal(t, 53, book.ItemsCount)
+	assert.Contains(t, book.TocYML, "type: META")
+	assert.Equal(t, mustParseTime(t, "2025-02-08T03:26:47.000Z"), book.ContentUpdatedAt)
+}
+
+func TestRepoService_UpdateRepo(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/repos/1294444", r.URL.Path)
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{
+			"name": "工程手册",
+			"toc":  "- [首页](index)",
+		}, req)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/create_repo.json"))
+	}))
+
+	book, _, err := client.RepoService.UpdateRepo(ctx, 1294444, &UpdateRepoRequest{
+		Name: new("工程手册"),
+		TOC:  new("- [首页](index)"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1294444, book.ID)
+}
+
+func TestRepoService_DeleteRepo(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/repos/yuque/handbook", r.URL.Path)
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/create_repo.json"))
+	}))
+
+	book, _, err := client.RepoService.DeleteRepo(ctx, "yuque/handbook")
+	require.NoError(t, err)
+	assert.Equal(t, 1294444, book.ID)
+}
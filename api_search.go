@@ -0,0 +1,117 @@
+package yuque
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+)
+
+type searchService struct {
+	client *Client
+}
+
+// SearchType 搜索类型 (doc:文档, repo:知识库)
+type SearchType string
+
+const (
+	SearchTypeDoc  SearchType = "doc"  // 文档
+	SearchTypeRepo SearchType = "repo" // 知识库
+)
+
+// Search 通用搜索
+func (s *searchService) Search(ctx context.Context, request *SearchRequest, opts ...RequestOption) (*SearchResponse, *Response, error) {
+	req, err := s.client.NewRequest(ctx, http.MethodGet, "search", request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results []*SearchResult
+	resp, err := s.client.Do(req, &results)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &SearchResponse{
+		Total:   resp.Total(),
+		Results: results,
+	}, resp, nil
+}
+
+type SearchRequest struct {
+	// 搜索关键词
+	Q *string `url:"q,omitempty"`
+
+	// 搜索类型 (doc:文档, repo:知识库)
+	Type *SearchType `url:"type,omitempty"`
+
+	// 搜索范围, 不填默认为搜索当前用户/团队
+	//
+	// 团队: group_login
+	// 知识库: group_login/book_slug
+	Scope *string `url:"scope,omitempty"`
+
+	// 页码 [分页参数]
+	Page *int `url:"page,omitempty"`
+
+	// 偏移量 [分页参数]
+	Offset *int `url:"offset,omitempty"`
+}
+
+type SearchResponse struct {
+	Total   int             `json:"total,omitempty"`
+	Results []*SearchResult `json:"results,omitempty"`
+}
+
+type SearchResult struct {
+	ID      int        `json:"id,omitempty"`
+	Type    SearchType `json:"type,omitempty"`
+	Title   string     `json:"title,omitempty"`   // 标题, 命中的关键词以 <em> 标签包裹
+	Summary string     `json:"summary,omitempty"` // 摘要, 命中的关键词以 <em> 标签包裹
+	URL     string     `json:"url,omitempty"`
+	Info    string     `json:"info,omitempty"` // 归属信息, 如 "团队 / 知识库"
+
+	// 以下字段根据搜索类型二选一
+	Doc  *Doc  `json:"-"` // 搜索类型为 doc 时的文档
+	Book *Book `json:"-"` // 搜索类型为 repo 时的知识库
+}
+
+func (r *SearchResult) UnmarshalJSON(data []byte) error {
+	type searchResult SearchResult
+	raw := struct {
+		*searchResult
+		Target json.RawMessage `json:"target,omitempty"`
+	}{searchResult: (*searchResult)(r)}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(raw.Target) == 0 || string(raw.Target) == "null" {
+		return nil
+	}
+
+	switch r.Type {
+	case SearchTypeDoc:
+		r.Doc = new(Doc)
+		return json.Unmarshal(raw.Target, r.Doc)
+	case SearchTypeRepo:
+		r.Book = new(Book)
+		return json.Unmarshal(raw.Target, r.Book)
+	}
+
+	return nil
+}
+
+var searchHighlightRegexp = regexp.MustCompile(`(?s)<em>(.*?)</em>`)
+
+// Highlights 返回标题及摘要中命中的关键词片段
+func (r *SearchResult) Highlights() []string {
+	var highlights []string
+	for _, text := range []string{r.Title, r.Summary} {
+		for _, match := range searchHighlightRegexp.FindAllStringSubmatch(text, -1) {
+			highlights = append(highlights, match[1])
+		}
+	}
+	return highlights
+}
@@ -0,0 +1,70 @@
+package yuque
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "会议室", r.URL.Query().Get("q"))
+		assert.Equal(t, "doc", r.URL.Query().Get("type"))
+		assert.Equal(t, "yuque/guide", r.URL.Query().Get("scope"))
+		assert.Equal(t, "2", r.URL.Query().Get("page"))
+		assert.Equal(t, "20", r.URL.Query().Get("offset"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/search/search_docs.json"))
+	}))
+
+	resp, _, err := client.SearchService.Search(ctx, &SearchRequest{
+		Q:      new("会议室"),
+		Type:   new(SearchTypeDoc),
+		Scope:  new("yuque/guide"),
+		Page:   new(2),
+		Offset: new(20),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 12, resp.Total)
+	require.Len(t, resp.Results, 1)
+
+	result := resp.Results[0]
+	assert.Equal(t, SearchTypeDoc, result.Type)
+	assert.Equal(t, "/yuque/guide/gvbblbqbgmzmew75", result.URL)
+	assert.Equal(t, []string{"会议室", "会议室"}, result.Highlights())
+	assert.Nil(t, result.Book)
+	require.NotNil(t, result.Doc)
+	assert.Equal(t, 200952222, result.Doc.ID)
+	assert.Equal(t, "会议室演示", result.Doc.Title)
+	require.NotNil(t, result.Doc.Book)
+	assert.Equal(t, "yuque/guide", result.Doc.Book.Namespace)
+}
+
+func TestSearchService_SearchRepos(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "repo", r.URL.Query().Get("type"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/search/search_repos.json"))
+	}))
+
+	resp, _, err := client.SearchService.Search(ctx, &SearchRequest{
+		Q:    new("指南"),
+		Type: new(SearchTypeRepo),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, resp.Total)
+	require.Len(t, resp.Results, 1)
+
+	result := resp.Results[0]
+	assert.Equal(t, []string{"指南"}, result.Highlights())
+	assert.Nil(t, result.Doc)
+	require.NotNil(t, result.Book)
+	assert.Equal(t, BookTypeBook, result.Book.Type)
+	assert.Equal(t, 53, result.Book.ItemsCount)
+}
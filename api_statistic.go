@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type statisticService struct {
@@ -77,6 +78,248 @@ type GetGroupStatisticsResponse struct {
 	TableCount             int     `json:"table_count,omitempty"`
 }
 
-// 团队.成员统计数据
-// 团队.知识库统计数据
-// 团队.文档统计数据
+// StatisticRange 统计时间范围 (0:全部, 30:近30天, 365:近一年)
+type StatisticRange int
+
+const (
+	StatisticRangeAll StatisticRange = 0   // 全部
+	StatisticRange30  StatisticRange = 30  // 近30天
+	StatisticRange365 StatisticRange = 365 // 近一年
+)
+
+// StatisticSortOrder 排序方向 (desc:降序, asc:升序)
+type StatisticSortOrder string
+
+const (
+	StatisticSortOrderDesc StatisticSortOrder = "desc" // 降序
+	StatisticSortOrderAsc  StatisticSortOrder = "asc"  // 升序
+)
+
+// MemberStatisticSortField 成员统计排序字段
+type MemberStatisticSortField string
+
+const (
+	MemberStatisticSortFieldWriteDocCount MemberStatisticSortField = "write_doc_count" // 创作文档数
+	MemberStatisticSortFieldWriteCount    MemberStatisticSortField = "write_count"     // 编辑次数
+	MemberStatisticSortFieldReadCount     MemberStatisticSortField = "read_count"      // 阅读量
+	MemberStatisticSortFieldLikeCount     MemberStatisticSortField = "like_count"      // 点赞量
+)
+
+// GetMemberStatistics 团队.成员统计数据
+func (s *statisticService) GetMemberStatistics(ctx context.Context, login any, request *GetMemberStatisticsRequest, opts ...RequestOption) (*GetMemberStatisticsResponse, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("groups/%s/statistics/members", lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var response GetMemberStatisticsResponse
+	resp, err := s.client.Do(req, &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &response, resp, nil
+}
+
+type GetMemberStatisticsRequest struct {
+	// 成员名 [过滤条件]
+	Name *string `url:"name,omitempty"`
+
+	// 时间范围 (0:全部, 30:近30天, 365:近一年)
+	Range *StatisticRange `url:"range,omitempty"`
+
+	// 页码 [分页参数]
+	Page *int `url:"page,omitempty"`
+
+	// 每页数量 [分页参数], 最大 20
+	Limit *int `url:"limit,omitempty"`
+
+	// 排序字段
+	SortField *MemberStatisticSortField `url:"sortField,omitempty"`
+
+	// 排序方向 (desc:降序, asc:升序)
+	SortOrder *StatisticSortOrder `url:"sortOrder,omitempty"`
+}
+
+type GetMemberStatisticsResponse struct {
+	Total   int                `json:"total,omitempty"`
+	Members []*MemberStatistic `json:"members,omitempty"`
+}
+
+type MemberStatistic struct {
+	UserID         int    `json:"user_id,omitempty"`
+	GroupID        int    `json:"group_id,omitempty"`
+	OrganizationID int    `json:"organization_id,omitempty"`
+	BizDate        string `json:"bizdate,omitempty"`
+	WriteDocCount  int    `json:"write_doc_count,omitempty"` // 创作文档数
+	WriteCount     int    `json:"write_count,omitempty"`     // 编辑次数
+	ReadCount      int    `json:"read_count,omitempty"`      // 阅读量
+	LikeCount      int    `json:"like_count,omitempty"`      // 点赞量
+	User           *User  `json:"user,omitempty"`
+}
+
+// BookStatisticSortField 知识库统计排序字段
+type BookStatisticSortField string
+
+const (
+	BookStatisticSortFieldContentUpdatedAt BookStatisticSortField = "content_updated_at_ms" // 内容更新时间
+	BookStatisticSortFieldWordCount        BookStatisticSortField = "word_count"            // 字数
+	BookStatisticSortFieldPostCount        BookStatisticSortField = "post_count"            // 文档数
+	BookStatisticSortFieldReadCount        BookStatisticSortField = "read_count"            // 阅读量
+	BookStatisticSortFieldLikeCount        BookStatisticSortField = "like_count"            // 点赞量
+	BookStatisticSortFieldWatchCount       BookStatisticSortField = "watch_count"           // 关注量
+	BookStatisticSortFieldCommentCount     BookStatisticSortField = "comment_count"         // 评论量
+)
+
+// GetBookStatistics 团队.知识库统计数据
+func (s *statisticService) GetBookStatistics(ctx context.Context, login any, request *GetBookStatisticsRequest, opts ...RequestOption) (*GetBookStatisticsResponse, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("groups/%s/statistics/books", lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var response GetBookStatisticsResponse
+	resp, err := s.client.Do(req, &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &response, resp, nil
+}
+
+type GetBookStatisticsRequest struct {
+	// 知识库名 [过滤条件]
+	Name *string `url:"name,omitempty"`
+
+	// 时间范围 (0:全部, 30:近30天, 365:近一年)
+	Range *StatisticRange `url:"range,omitempty"`
+
+	// 页码 [分页参数]
+	Page *int `url:"page,omitempty"`
+
+	// 每页数量 [分页参数], 最大 20
+	Limit *int `url:"limit,omitempty"`
+
+	// 排序字段
+	SortField *BookStatisticSortField `url:"sortField,omitempty"`
+
+	// 排序方向 (desc:降序, asc:升序)
+	SortOrder *StatisticSortOrder `url:"sortOrder,omitempty"`
+}
+
+type GetBookStatisticsResponse struct {
+	Total int              `json:"total,omitempty"`
+	Books []*BookStatistic `json:"books,omitempty"`
+}
+
+type BookStatistic struct {
+	BookID             int        `json:"book_id,omitempty"`
+	BizDate            string     `json:"bizdate,omitempty"`
+	UserID             int        `json:"user_id,omitempty"`
+	OrganizationID     int        `json:"organization_id,omitempty"`
+	Slug               string     `json:"slug,omitempty"`
+	Name               string     `json:"name,omitempty"`
+	Type               BookType   `json:"type,omitempty"`
+	Public             AccessType `json:"public,omitempty"`
+	WordCount          int        `json:"word_count,omitempty"`            // 字数
+	PostCount          int        `json:"post_count,omitempty"`            // 文档数
+	ReadCount          int        `json:"read_count,omitempty"`            // 阅读量
+	LikeCount          int        `json:"like_count,omitempty"`            // 点赞量
+	WatchCount         int        `json:"watch_count,omitempty"`           // 关注量
+	CommentCount       int        `json:"comment_count,omitempty"`         // 评论量
+	ContentUpdatedAt   time.Time  `json:"content_updated_at,omitzero"`     // 内容更新时间
+	ContentUpdatedAtMs int64      `json:"content_updated_at_ms,omitempty"` // 内容更新时间 (毫秒时间戳)
+	CreatedAt          time.Time  `json:"created_at,omitzero"`
+	UpdatedAt          time.Time  `json:"updated_at,omitzero"`
+	User               *User      `json:"user,omitempty"`
+}
+
+// DocStatisticSortField 文档统计排序字段
+type DocStatisticSortField string
+
+const (
+	DocStatisticSortFieldContentUpdatedAt DocStatisticSortField = "content_updated_at" // 内容更新时间
+	DocStatisticSortFieldWordCount        DocStatisticSortField = "word_count"         // 字数
+	DocStatisticSortFieldReadCount        DocStatisticSortField = "read_count"         // 阅读量
+	DocStatisticSortFieldLikeCount        DocStatisticSortField = "like_count"         // 点赞量
+	DocStatisticSortFieldCommentCount     DocStatisticSortField = "comment_count"      // 评论量
+	DocStatisticSortFieldCreatedAt        DocStatisticSortField = "created_at"         // 创建时间
+)
+
+// GetDocStatistics 团队.文档统计数据
+func (s *statisticService) GetDocStatistics(ctx context.Context, login any, request *GetDocStatisticsRequest, opts ...RequestOption) (*GetDocStatisticsResponse, *Response, error) {
+	lid, err := parseID(login)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, fmt.Sprintf("groups/%s/statistics/docs", lid), request, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var response GetDocStatisticsResponse
+	resp, err := s.client.Do(req, &response)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return &response, resp, nil
+}
+
+type GetDocStatisticsRequest struct {
+	// 知识库 ID [过滤条件]
+	BookID *int `url:"bookId,omitempty"`
+
+	// 文档标题 [过滤条件]
+	Name *string `url:"name,omitempty"`
+
+	// 时间范围 (0:全部, 30:近30天, 365:近一年)
+	Range *StatisticRange `url:"range,omitempty"`
+
+	// 页码 [分页参数]
+	Page *int `url:"page,omitempty"`
+
+	// 每页数量 [分页参数], 最大 20
+	Limit *int `url:"limit,omitempty"`
+
+	// 排序字段
+	SortField *DocStatisticSortField `url:"sortField,omitempty"`
+
+	// 排序方向 (desc:降序, asc:升序)
+	SortOrder *StatisticSortOrder `url:"sortOrder,omitempty"`
+}
+
+type GetDocStatisticsResponse struct {
+	Total int             `json:"total,omitempty"`
+	Docs  []*DocStatistic `json:"docs,omitempty"`
+}
+
+type DocStatistic struct {
+	DocID            int       `json:"doc_id,omitempty"`
+	BizDate          string    `json:"bizdate,omitempty"`
+	BookID           int       `json:"book_id,omitempty"`
+	UserID           int       `json:"user_id,omitempty"`
+	OrganizationID   int       `json:"organization_id,omitempty"`
+	Slug             string    `json:"slug,omitempty"`
+	Title            string    `json:"title,omitempty"`
+	WordCount        int       `json:"word_count,omitempty"`    // 字数
+	ReadCount        int       `json:"read_count,omitempty"`    // 阅读量
+	LikeCount        int       `json:"like_count,omitempty"`    // 点赞量
+	CommentCount     int       `json:"comment_count,omitempty"` // 评论量
+	ContentUpdatedAt time.Time `json:"content_updated_at,omitzero"`
+	CreatedAt        time.Time `json:"created_at,omitzero"`
+	UpdatedAt        time.Time `json:"updated_at,omitzero"`
+	User             *User     `json:"user,omitempty"`
+	Book             *Book     `json:"book,omitempty"`
+}
@@ -65,3 +65,106 @@ func TestStatisticService_GetGroupStatistics(t *testing.T) {
 		TableCount:             3,
 	}, resp)
 }
+
+func TestStatisticService_GetMemberStatistics(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/groups/group_name/statistics/members", r.URL.Path)
+		assert.Equal(t, "张", r.URL.Query().Get("name"))
+		assert.Equal(t, "30", r.URL.Query().Get("range"))
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		assert.Equal(t, "20", r.URL.Query().Get("limit"))
+		assert.Equal(t, "write_doc_count", r.URL.Query().Get("sortField"))
+		assert.Equal(t, "desc", r.URL.Query().Get("sortOrder"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/statistic/get_member_statistics.json"))
+	}))
+
+	resp, _, err := client.StatisticService.GetMemberStatistics(ctx, "group_name", &GetMemberStatisticsRequest{
+		Name:      new("张"),
+		Range:     new(StatisticRange30),
+		Page:      new(1),
+		Limit:     new(20),
+		SortField: new(MemberStatisticSortFieldWriteDocCount),
+		SortOrder: new(StatisticSortOrderDesc),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 26, resp.Total)
+	require.Len(t, resp.Members, 2)
+	assert.Equal(t, 42, resp.Members[0].WriteDocCount)
+	assert.Equal(t, 1380, resp.Members[0].WriteCount)
+	require.NotNil(t, resp.Members[0].User)
+	assert.Equal(t, "张三", resp.Members[0].User.Name)
+	assert.Nil(t, resp.Members[1].User)
+}
+
+func TestStatisticService_GetBookStatistics(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/groups/group_name/statistics/books", r.URL.Path)
+		assert.Equal(t, "0", r.URL.Query().Get("range"))
+		assert.Equal(t, "read_count", r.URL.Query().Get("sortField"))
+		assert.Equal(t, "asc", r.URL.Query().Get("sortOrder"))
+		assert.False(t, r.URL.Query().Has("name"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/statistic/get_book_statistics.json"))
+	}))
+
+	resp, _, err := client.StatisticService.GetBookStatistics(ctx, "group_name", &GetBookStatisticsRequest{
+		Range:     new(StatisticRangeAll),
+		SortField: new(BookStatisticSortFieldReadCount),
+		SortOrder: new(StatisticSortOrderAsc),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 28, resp.Total)
+	require.Len(t, resp.Books, 1)
+	assert.Equal(t, &BookStatistic{
+		BookID:             1292222,
+		BizDate:            "20250301",
+		UserID:             1781111,
+		OrganizationID:     35111,
+		Slug:               "guide",
+		Name:               "新人指南",
+		Type:               BookTypeBook,
+		Public:             AccessTypePrivate,
+		WordCount:          38211,
+		PostCount:          53,
+		ReadCount:          8120,
+		LikeCount:          31,
+		WatchCount:         26,
+		CommentCount:       12,
+		ContentUpdatedAt:   mustParseTime(t, "2025-02-08T03:26:47.000Z"),
+		ContentUpdatedAtMs: 1738985207000,
+		CreatedAt:          mustParseTime(t, "2020-07-16T03:05:11.000Z"),
+		UpdatedAt:          mustParseTime(t, "2025-02-08T03:26:47.000Z"),
+	}, resp.Books[0])
+}
+
+func TestStatisticService_GetDocStatistics(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/groups/group_name/statistics/docs", r.URL.Path)
+		assert.Equal(t, "1292222", r.URL.Query().Get("bookId"))
+		assert.Equal(t, "365", r.URL.Query().Get("range"))
+		assert.Equal(t, "word_count", r.URL.Query().Get("sortField"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/statistic/get_doc_statistics.json"))
+	}))
+
+	resp, _, err := client.StatisticService.GetDocStatistics(ctx, "group_name", &GetDocStatisticsRequest{
+		BookID:    new(1292222),
+		Range:     new(StatisticRange365),
+		SortField: new(DocStatisticSortFieldWordCount),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1623, resp.Total)
+	require.Len(t, resp.Docs, 1)
+	assert.Equal(t, 200952222, resp.Docs[0].DocID)
+	assert.Equal(t, "会议室演示", resp.Docs[0].Title)
+	assert.Equal(t, 128, resp.Docs[0].ReadCount)
+	require.NotNil(t, resp.Docs[0].Book)
+	assert.Equal(t, "yuque/guide", resp.Docs[0].Book.Namespace)
+}
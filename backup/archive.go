@@ -0,0 +1,206 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the archive entries.
+const (
+	headerName   = "backup.json"
+	manifestName = "manifest.json"
+	reposDir     = "repos/"
+)
+
+func repoName(repoID int) string {
+	return fmt.Sprintf("%s%d/repo.json", reposDir, repoID)
+}
+
+func docName(repoID, docID int) string {
+	return fmt.Sprintf("%s%d/docs/%d.json", reposDir, repoID, docID)
+}
+
+func tocName(repoID int) string {
+	return fmt.Sprintf("%s%d/toc.json", reposDir, repoID)
+}
+
+// parseName returns the kind of a repo entry, "repo", "doc" or "toc", with
+// its repo and doc IDs.
+func parseName(name string) (kind string, repoID, docID int, ok bool) {
+	rest, found := strings.CutPrefix(name, reposDir)
+	if !found {
+		return "", 0, 0, false
+	}
+
+	parts := strings.Split(rest, "/")
+	repoID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", 0, 0, false
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "repo.json":
+		return "repo", repoID, 0, true
+	case len(parts) == 2 && parts[1] == "toc.json":
+		return "toc", repoID, 0, true
+	case len(parts) == 3 && parts[1] == "docs":
+		docID, err := strconv.Atoi(strings.TrimSuffix(parts[2], ".json"))
+		if err != nil || !strings.HasSuffix(parts[2], ".json") {
+			return "", 0, 0, false
+		}
+		return "doc", repoID, docID, true
+	}
+	return "", 0, 0, false
+}
+
+// header is the first entry of an archive.
+type header struct {
+	Version   int       `json:"version"`
+	Group     string    `json:"group"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// archiveWriter writes a gzipped tar archive, encrypted if a key is given.
+type archiveWriter struct {
+	tw      *tar.Writer
+	gz      *gzip.Writer
+	enc     *encryptWriter
+	modTime time.Time
+}
+
+func newArchiveWriter(w io.Writer, key []byte, modTime time.Time) (*archiveWriter, error) {
+	a := &archiveWriter{modTime: modTime}
+	if key != nil {
+		enc, err := newEncryptWriter(w, key)
+		if err != nil {
+			return nil, err
+		}
+		a.enc, w = enc, enc
+	}
+	a.gz = gzip.NewWriter(w)
+	a.tw = tar.NewWriter(a.gz)
+	return a, nil
+}
+
+func (a *archiveWriter) write(name string, data []byte) error {
+	err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     int64(len(data)),
+		Mode:     0o644,
+		ModTime:  a.modTime,
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = a.tw.Write(data)
+	return err
+}
+
+func (a *archiveWriter) writeJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.write(name, append(data, '\n'))
+}
+
+// close flushes the archive, it does not close the underlying writer.
+func (a *archiveWriter) close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	if err := a.gz.Close(); err != nil {
+		return err
+	}
+	if a.enc != nil {
+		return a.enc.Close()
+	}
+	return nil
+}
+
+// archiveReader reads the entries of an archive in order.
+type archiveReader struct {
+	tr     *tar.Reader
+	header *header
+}
+
+// openArchive opens the archive, decrypting it with the key if encrypted, and reads its header.
+func openArchive(r io.Reader, key []byte) (*archiveReader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(encryptedMagic)); bytes.Equal(magic, []byte(encryptedMagic)) {
+		if key == nil {
+			return nil, ErrEncrypted
+		}
+		_, _ = br.Discard(len(encryptedMagic))
+
+		dec, err := newDecryptReader(br, key)
+		if err != nil {
+			return nil, err
+		}
+		r = dec
+	} else {
+		r = br
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("backup: read archive: %w", err)
+	}
+	a := &archiveReader{tr: tar.NewReader(gz)}
+
+	name, data, err := a.next()
+	if errors.Is(err, io.EOF) || (err == nil && name != headerName) {
+		return nil, errors.New("backup: read archive: missing " + headerName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &a.header); err != nil {
+		return nil, fmt.Errorf("backup: read archive: %w", err)
+	}
+	if a.header.Version < 1 || a.header.Version > FormatVersion {
+		return nil, fmt.Errorf("backup: unsupported archive version %d", a.header.Version)
+	}
+
+	return a, nil
+}
+
+// next returns the next regular entry, io.EOF at the end of the archive.
+func (a *archiveReader) next() (string, []byte, error) {
+	for {
+		hdr, err := a.tr.Next()
+		if errors.Is(err, io.EOF) {
+			return "", nil, io.EOF
+		}
+		if err != nil {
+			return "", nil, readError(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(a.tr)
+		if err != nil {
+			return "", nil, readError(err)
+		}
+		return hdr.Name, data, nil
+	}
+}
+
+func readError(err error) error {
+	if errors.Is(err, ErrDecrypt) {
+		return err
+	}
+	return fmt.Errorf("backup: read archive: %w", err)
+}
@@ -0,0 +1,305 @@
+// Package backup archives the repos of a group and restores them, for
+// disaster recovery.
+//
+// An archive is a gzipped tar file, optionally encrypted with AES-GCM,
+// holding the API responses of each repo, doc and TOC as JSON, so that every
+// available body format of the docs is kept:
+//
+//	backup.json                 format version, group and time of the backup
+//	repos/<id>/repo.json        repo details
+//	repos/<id>/docs/<id>.json   doc details, with all bodies
+//	repos/<id>/toc.json         TOC
+//	manifest.json               index of the repos and docs, see Manifest
+//
+// Archives are written and read as streams, so that a group of any size is
+// backed up without holding it in memory.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// FormatVersion is the version of the archive format written by Backup.
+const FormatVersion = 1
+
+// Manifest indexes the repos and docs of an archive.
+type Manifest struct {
+	Version   int          `json:"version"`
+	Group     string       `json:"group"`
+	CreatedAt time.Time    `json:"created_at"`
+	Repos     []*RepoEntry `json:"repos"`
+}
+
+// RepoEntry is a repo of an archive.
+type RepoEntry struct {
+	ID        int         `json:"id"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Docs      []*DocEntry `json:"docs"`
+}
+
+// DocEntry is a doc of an archive.
+type DocEntry struct {
+	ID               int       `json:"id"`
+	Slug             string    `json:"slug"`
+	Title            string    `json:"title"`
+	ContentUpdatedAt time.Time `json:"content_updated_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	// Reused reports whether the doc was copied from the previous archive.
+	Reused bool `json:"reused,omitempty"`
+}
+
+// Option configures Backup, Restore and ReadManifest.
+type Option func(*options)
+
+type options struct {
+	key      []byte
+	previous io.Reader
+	resume   bool
+}
+
+// WithKey encrypts or decrypts the archive with AES-GCM, the key being 16, 24
+// or 32 bytes long for AES-128, AES-192 or AES-256.
+func WithKey(key []byte) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// WithPrevious makes Backup incremental: docs that did not change
+// since the previous archive of the group are copied from it instead of
+// being fetched again. The previous archive is decrypted with the same key.
+func WithPrevious(previous io.Reader) Option {
+	return func(o *options) {
+		o.previous = previous
+	}
+}
+
+// WithResume makes Restore continue a restore that failed part way: repos
+// of the group with the slug of an archived repo, and docs of these repos
+// with the slug of an archived doc, are kept instead of being created again.
+func WithResume() Option {
+	return func(o *options) {
+		o.resume = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// backupRepo is a repo being backed up, with the docs left to fetch.
+type backupRepo struct {
+	entry   *RepoEntry
+	pending map[int]*pendingDoc
+}
+
+// pendingDoc is a doc left to fetch, as listed.
+type pendingDoc struct {
+	entry  *DocEntry
+	listed *yuque.Doc
+}
+
+// Backup writes an archive of every repo of the group to w, with the details
+// of each doc and the TOC, and returns its manifest.
+//
+// group: 团队 ID 或 登录名(login)
+func Backup(ctx context.Context, client *yuque.Client, group string, w io.Writer, opts ...Option) (*Manifest, error) {
+	o := newOptions(opts)
+	manifest := &Manifest{Version: FormatVersion, Group: group, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+
+	a, err := newArchiveWriter(w, o.key, manifest.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.writeJSON(headerName, &header{Version: manifest.Version, Group: group, CreatedAt: manifest.CreatedAt}); err != nil {
+		return nil, err
+	}
+
+	// repos first, so that Restore creates them before their docs
+	var repos []*backupRepo
+	byID := make(map[int]*backupRepo)
+	for book, err := range client.RepoService.AllGroupRepos(ctx, group, nil) {
+		if err != nil {
+			return nil, err
+		}
+
+		repo, err := backupRepoDetails(ctx, client, a, book)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, repo)
+		byID[book.ID] = repo
+		manifest.Repos = append(manifest.Repos, repo.entry)
+	}
+
+	if o.previous != nil {
+		if err := reuseDocs(o.previous, o.key, a, byID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, repo := range repos {
+		docs := repo.entry.Docs[:0]
+		for _, doc := range repo.entry.Docs {
+			if _, ok := repo.pending[doc.ID]; !ok {
+				docs = append(docs, doc)
+				continue
+			}
+
+			data, _, err := yuque.Get[json.RawMessage](ctx, client, fmt.Sprintf("repos/%d/docs/%d", repo.entry.ID, doc.ID), nil)
+			if errors.Is(err, yuque.ErrNotFound) {
+				// deleted since listed
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("backup: doc %s/%s: %w", repo.entry.Namespace, doc.Slug, err)
+			}
+			if err := a.write(docName(repo.entry.ID, doc.ID), *data); err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+		repo.entry.Docs = docs
+	}
+
+	// TOCs last, so that Restore places docs already created
+	for _, repo := range repos {
+		data, _, err := yuque.Get[json.RawMessage](ctx, client, fmt.Sprintf("repos/%d/toc", repo.entry.ID), nil)
+		if err != nil {
+			return nil, fmt.Errorf("backup: toc of %s: %w", repo.entry.Namespace, err)
+		}
+		if err := a.write(tocName(repo.entry.ID), *data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.writeJSON(manifestName, manifest); err != nil {
+		return nil, err
+	}
+	if err := a.close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// backupRepoDetails writes the details of the repo and lists its docs.
+func backupRepoDetails(ctx context.Context, client *yuque.Client, a *archiveWriter, book *yuque.Book) (*backupRepo, error) {
+	data, _, err := yuque.Get[json.RawMessage](ctx, client, fmt.Sprintf("repos/%d", book.ID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("backup: repo %s: %w", book.Namespace, err)
+	}
+	if err := a.write(repoName(book.ID), *data); err != nil {
+		return nil, err
+	}
+
+	repo := &backupRepo{
+		entry:   &RepoEntry{ID: book.ID, Namespace: book.Namespace, Name: book.Name, Docs: []*DocEntry{}},
+		pending: make(map[int]*pendingDoc),
+	}
+	for doc, err := range client.DocService.AllDocs(ctx, book.ID, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("backup: docs of %s: %w", book.Namespace, err)
+		}
+
+		entry := &DocEntry{ID: doc.ID, Slug: doc.Slug, Title: doc.Title, ContentUpdatedAt: doc.ContentUpdatedAt, UpdatedAt: doc.UpdatedAt}
+		repo.entry.Docs = append(repo.entry.Docs, entry)
+		repo.pending[doc.ID] = &pendingDoc{entry: entry, listed: doc}
+	}
+	return repo, nil
+}
+
+// reuseDocs copies the docs of the previous archive that did not change
+// since, neither their content nor their metadata such as the title, slug or
+// visibility, leaving the others pending.
+func reuseDocs(previous io.Reader, key []byte, a *archiveWriter, repos map[int]*backupRepo) error {
+	p, err := openArchive(previous, key)
+	if err != nil {
+		return fmt.Errorf("previous archive: %w", err)
+	}
+
+	for {
+		name, data, err := p.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("previous archive: %w", err)
+		}
+
+		kind, repoID, docID, ok := parseName(name)
+		if !ok || kind != "doc" || repos[repoID] == nil {
+			continue
+		}
+		pending, ok := repos[repoID].pending[docID]
+		if !ok {
+			continue
+		}
+
+		var doc yuque.Doc
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("previous archive: %s: %w", name, err)
+		}
+		if !unchanged(&doc, pending.listed) {
+			continue
+		}
+
+		if err := a.write(name, data); err != nil {
+			return err
+		}
+		pending.entry.Reused = true
+		delete(repos[repoID].pending, docID)
+	}
+}
+
+// unchanged reports whether the archived doc is up to date with the listed one.
+func unchanged(archived, listed *yuque.Doc) bool {
+	return archived.ContentUpdatedAt.Equal(listed.ContentUpdatedAt) &&
+		archived.UpdatedAt.Equal(listed.UpdatedAt) &&
+		archived.Title == listed.Title &&
+		archived.Slug == listed.Slug &&
+		archived.Public == listed.Public
+}
+
+// ReadManifest reads the manifest of the archive, verifying the archive along
+// the way.
+func ReadManifest(r io.Reader, opts ...Option) (*Manifest, error) {
+	a, err := openArchive(r, newOptions(opts).key)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *Manifest
+	for {
+		name, data, err := a.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if name == manifestName {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("backup: read archive: %w", err)
+			}
+		}
+	}
+
+	if manifest == nil {
+		return nil, errors.New("backup: read archive: missing " + manifestName)
+	}
+	return manifest, nil
+}
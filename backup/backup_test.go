@@ -0,0 +1,241 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+	"github.com/flc1125/go-yuque/yuquetest"
+)
+
+var ctx = context.Background()
+
+func newTestClient(t *testing.T) *yuque.Client {
+	srv := yuquetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.NewClient(yuquetest.Token)
+	require.NoError(t, err)
+	me, _, err := client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+	srv.AddGroup("team", "Team", me.ID)
+	srv.AddGroup("restored", "Restored", me.ID)
+
+	return client
+}
+
+// newTestRepo creates a repo of the team with a doc under a title, a lake doc and a link.
+func newTestRepo(t *testing.T, client *yuque.Client, slug string) *yuque.Book {
+	book, _, err := client.RepoService.CreateGroupRepo(ctx, "team", &yuque.CreateRepoRequest{
+		Name:        new("Repo " + slug),
+		Slug:        new(slug),
+		Description: new("about " + slug),
+	})
+	require.NoError(t, err)
+
+	intro, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{Slug: new("intro"), Title: new("Intro"), Body: new("# Intro")})
+	require.NoError(t, err)
+	lake, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{
+		Slug:   new("lake"),
+		Title:  new("Lake"),
+		Format: new(yuque.DocFormatLake),
+		Body:   new(`<!doctype lake><p>lake</p>`),
+	})
+	require.NoError(t, err)
+
+	desired := []*yuque.DesiredTOCNode{
+		{Title: "Guide", Children: []*yuque.DesiredTOCNode{{DocID: intro.ID}, {DocID: lake.ID}}},
+		{Title: "Yuque", URL: "https://www.yuque.com"},
+	}
+	_, _, err = client.DocService.ReconcileTOC(ctx, book.ID, &yuque.ReconcileTOCRequest{Desired: desired})
+	require.NoError(t, err)
+
+	return book
+}
+
+func tocPaths(t *testing.T, client *yuque.Client, bookID any) []string {
+	tocs, _, err := client.DocService.GetTOCs(ctx, bookID)
+	require.NoError(t, err)
+
+	var paths []string
+	for node := range yuque.NewTOCTree(tocs).All() {
+		paths = append(paths, string(node.Type)+" "+node.Path())
+	}
+	return paths
+}
+
+func TestBackupRestore(t *testing.T) {
+	client := newTestClient(t)
+	newTestRepo(t, client, "handbook")
+	newTestRepo(t, client, "wiki")
+
+	var archive bytes.Buffer
+	manifest, err := Backup(ctx, client, "team", &archive)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, manifest.Version)
+	require.Len(t, manifest.Repos, 2)
+	assert.Equal(t, "team/handbook", manifest.Repos[0].Namespace)
+	require.Len(t, manifest.Repos[0].Docs, 2)
+	assert.Equal(t, "intro", manifest.Repos[0].Docs[0].Slug)
+
+	read, err := ReadManifest(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Repos[1].Docs[1].ID, read.Repos[1].Docs[1].ID)
+
+	result, err := Restore(ctx, client, "restored", bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, result.Repos, 2)
+	assert.Equal(t, "restored/handbook", result.Repos[0].Namespace)
+	assert.Equal(t, 4, result.Docs)
+	assert.Empty(t, result.Skipped)
+
+	book, _, err := client.RepoService.GetRepo(ctx, "restored/wiki")
+	require.NoError(t, err)
+	assert.Equal(t, "Repo wiki", book.Name)
+	assert.Equal(t, "about wiki", book.Description)
+
+	doc, _, err := client.DocService.GetDoc(ctx, "restored/wiki", "lake")
+	require.NoError(t, err)
+	assert.Equal(t, yuque.DocFormatLake, *doc.Format)
+	assert.Equal(t, `<!doctype lake><p>lake</p>`, *doc.BodyLake)
+
+	assert.Equal(t, tocPaths(t, client, "team/wiki"), tocPaths(t, client, "restored/wiki"))
+	assert.Equal(t, []string{"TITLE Guide", "DOC Guide/Intro", "DOC Guide/Lake", "LINK Yuque"}, tocPaths(t, client, "restored/handbook"))
+
+	// the repos exist now
+	_, err = Restore(ctx, client, "restored", bytes.NewReader(archive.Bytes()))
+	assert.ErrorContains(t, err, "create repo team/handbook")
+}
+
+func TestRestore_Resume(t *testing.T) {
+	client := newTestClient(t)
+	newTestRepo(t, client, "handbook")
+	newTestRepo(t, client, "wiki")
+
+	var archive bytes.Buffer
+	_, err := Backup(ctx, client, "team", &archive)
+	require.NoError(t, err)
+
+	// left by a restore failing after the first doc
+	book, _, err := client.RepoService.CreateGroupRepo(ctx, "restored", &yuque.CreateRepoRequest{Name: new("Repo handbook"), Slug: new("handbook")})
+	require.NoError(t, err)
+	_, _, err = client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{Slug: new("intro"), Title: new("Intro"), Body: new("# Intro")})
+	require.NoError(t, err)
+
+	result, err := Restore(ctx, client, "restored", bytes.NewReader(archive.Bytes()))
+	assert.ErrorContains(t, err, "create repo team/handbook")
+	assert.Empty(t, result.Repos)
+
+	result, err = Restore(ctx, client, "restored", bytes.NewReader(archive.Bytes()), WithResume())
+	require.NoError(t, err)
+	require.Len(t, result.Resumed, 1)
+	assert.Equal(t, book.ID, result.Resumed[0].ID)
+	assert.Equal(t, 1, result.ResumedDocs)
+	require.Len(t, result.Repos, 1)
+	assert.Equal(t, "restored/wiki", result.Repos[0].Namespace)
+	assert.Equal(t, 3, result.Docs)
+
+	assert.Equal(t, tocPaths(t, client, "team/handbook"), tocPaths(t, client, "restored/handbook"))
+	assert.Equal(t, tocPaths(t, client, "team/wiki"), tocPaths(t, client, "restored/wiki"))
+
+	// a complete restore is resumed as is
+	result, err = Restore(ctx, client, "restored", bytes.NewReader(archive.Bytes()), WithResume())
+	require.NoError(t, err)
+	assert.Empty(t, result.Repos)
+	assert.Len(t, result.Resumed, 2)
+	assert.Equal(t, 4, result.ResumedDocs)
+	assert.Zero(t, result.Docs)
+}
+
+func TestBackup_Incremental(t *testing.T) {
+	client := newTestClient(t)
+	book := newTestRepo(t, client, "handbook")
+
+	var first bytes.Buffer
+	_, err := Backup(ctx, client, "team", &first, WithKey(testKey))
+	require.NoError(t, err)
+
+	_, _, err = client.DocService.UpdateDoc(ctx, book.ID, "intro", &yuque.UpdateDocRequest{Body: new("# Intro v2")})
+	require.NoError(t, err)
+	_, _, err = client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{Slug: new("new"), Body: new("new")})
+	require.NoError(t, err)
+
+	var second bytes.Buffer
+	manifest, err := Backup(ctx, client, "team", &second, WithKey(testKey), WithPrevious(&first))
+	require.NoError(t, err)
+
+	reused := make(map[string]bool)
+	for _, doc := range manifest.Repos[0].Docs {
+		reused[doc.Slug] = doc.Reused
+	}
+	assert.Equal(t, map[string]bool{"intro": false, "lake": true, "new": false}, reused)
+
+	// the incremental archive is complete
+	result, err := Restore(ctx, client, "restored", bytes.NewReader(second.Bytes()), WithKey(testKey))
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Docs)
+
+	doc, _, err := client.DocService.GetDoc(ctx, "restored/handbook", "intro")
+	require.NoError(t, err)
+	assert.Equal(t, "# Intro v2", *doc.Body)
+
+	// metadata changes are fetched too
+	_, _, err = client.DocService.UpdateDoc(ctx, book.ID, "lake", &yuque.UpdateDocRequest{Title: new("Lake v2")})
+	require.NoError(t, err)
+
+	manifest, err = Backup(ctx, client, "team", io.Discard, WithKey(testKey), WithPrevious(bytes.NewReader(second.Bytes())))
+	require.NoError(t, err)
+	reused = make(map[string]bool)
+	for _, doc := range manifest.Repos[0].Docs {
+		reused[doc.Slug] = doc.Reused
+	}
+	assert.Equal(t, map[string]bool{"intro": true, "lake": false, "new": true}, reused)
+}
+
+func TestBackup_Encrypted(t *testing.T) {
+	client := newTestClient(t)
+	newTestRepo(t, client, "handbook")
+
+	var archive bytes.Buffer
+	_, err := Backup(ctx, client, "team", &archive, WithKey(testKey))
+	require.NoError(t, err)
+	assert.NotContains(t, archive.String(), "Intro")
+
+	_, err = ReadManifest(bytes.NewReader(archive.Bytes()))
+	assert.ErrorIs(t, err, ErrEncrypted)
+
+	_, err = Restore(ctx, client, "restored", bytes.NewReader(archive.Bytes()), WithKey(bytes.Repeat([]byte{8}, 32)))
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = ReadManifest(bytes.NewReader(archive.Bytes()), WithKey(testKey))
+	require.NoError(t, err)
+
+	_, err = Backup(ctx, client, "team", &archive, WithKey([]byte("short")))
+	assert.Error(t, err)
+}
+
+func TestParseName(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		kind          string
+		repoID, docID int
+	}{
+		{name: repoName(1), kind: "repo", repoID: 1},
+		{name: docName(1, 2), kind: "doc", repoID: 1, docID: 2},
+		{name: tocName(1), kind: "toc", repoID: 1},
+		{name: manifestName},
+		{name: "repos/x/repo.json"},
+		{name: "repos/1/docs/2.md"},
+	} {
+		kind, repoID, docID, ok := parseName(tt.name)
+		assert.Equal(t, tt.kind != "", ok, tt.name)
+		assert.Equal(t, tt.kind, kind, tt.name)
+		assert.Equal(t, tt.repoID, repoID, tt.name)
+		assert.Equal(t, tt.docID, docID, tt.name)
+	}
+}
@@ -0,0 +1,193 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// An encrypted archive is the magic, a random nonce prefix, and the archive
+// split into chunks sealed with AES-GCM. Each chunk is prefixed by its sealed
+// length, whose high bit flags the final chunk. The nonce of a chunk is the
+// prefix, its index and the final flag, so that chunks cannot be reordered,
+// dropped or truncated without failing to open.
+const (
+	encryptedMagic  = "YQBKGCM1"
+	noncePrefixSize = 7
+	chunkSize       = 64 << 10
+	finalChunk      = 1 << 31
+)
+
+var (
+	// ErrEncrypted is returned when reading an encrypted archive without a key.
+	ErrEncrypted = errors.New("backup: archive is encrypted, a key is required")
+
+	// ErrDecrypt is returned when an encrypted archive is corrupted or the key is wrong.
+	ErrDecrypt = errors.New("backup: archive is corrupted or the key is wrong")
+)
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of the chunk.
+func chunkNonce(prefix []byte, index uint32, final bool) []byte {
+	nonce := make([]byte, 0, noncePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, index)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// encryptWriter encrypts the archive written to it, until closed.
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	buf    []byte
+}
+
+func newEncryptWriter(w io.Writer, key []byte) (*encryptWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, encryptedMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		k := min(chunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:k]...)
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// Close writes the final chunk, it does not close the underlying writer.
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(final bool) error {
+	if e.index == math.MaxUint32 {
+		return errors.New("backup: archive too large to encrypt")
+	}
+
+	sealed := e.aead.Seal(nil, chunkNonce(e.prefix, e.index, final), e.buf, nil)
+	size := uint32(len(sealed))
+	if final {
+		size |= finalChunk
+	}
+	if _, err := e.w.Write(binary.BigEndian.AppendUint32(nil, size)); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+
+	e.buf = e.buf[:0]
+	e.index++
+	return nil
+}
+
+// decryptReader decrypts an archive written by encryptWriter, after its magic.
+type decryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint32
+	buf    []byte
+	done   bool
+}
+
+func newDecryptReader(r io.Reader, key []byte) (*decryptReader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, ErrDecrypt
+	}
+	return &decryptReader{r: r, aead: aead, prefix: prefix}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	var header [4]byte
+	if _, err := io.ReadFull(d.r, header[:]); err != nil {
+		return ErrDecrypt
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	final := size&finalChunk != 0
+	size &^= finalChunk
+	if size > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrDecrypt
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return ErrDecrypt
+	}
+	plain, err := d.aead.Open(sealed[:0], chunkNonce(d.prefix, d.index, final), sealed, nil)
+	if err != nil {
+		return ErrDecrypt
+	}
+
+	if final {
+		// nothing may follow the final chunk
+		if n, _ := d.r.Read(header[:1]); n > 0 {
+			return ErrDecrypt
+		}
+		d.done = true
+	}
+	d.buf = plain
+	d.index++
+	return nil
+}
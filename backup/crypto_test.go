@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func encrypt(t *testing.T, plain []byte) []byte {
+	var buf bytes.Buffer
+	e, err := newEncryptWriter(&buf, testKey)
+	require.NoError(t, err)
+	_, err = e.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, e.Close())
+	return buf.Bytes()
+}
+
+func decrypt(encrypted []byte, key []byte) ([]byte, error) {
+	r := bytes.NewReader(encrypted[len(encryptedMagic):])
+	d, err := newDecryptReader(r, key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(d)
+}
+
+func TestEncrypt(t *testing.T) {
+	for _, size := range []int{0, 1, chunkSize, 3*chunkSize + 5} {
+		plain := make([]byte, size)
+		for i := range plain {
+			plain[i] = byte(rand.N(256))
+		}
+
+		encrypted := encrypt(t, plain)
+		assert.Equal(t, encryptedMagic, string(encrypted[:len(encryptedMagic)]))
+
+		got, err := decrypt(encrypted, testKey)
+		require.NoError(t, err)
+		assert.Equal(t, plain, got)
+	}
+}
+
+func TestDecrypt_Invalid(t *testing.T) {
+	encrypted := encrypt(t, bytes.Repeat([]byte("yuque"), chunkSize/2))
+
+	_, err := decrypt(encrypted, bytes.Repeat([]byte{8}, 32))
+	assert.ErrorIs(t, err, ErrDecrypt, "wrong key")
+
+	_, err = decrypt(encrypted[:len(encrypted)-1], testKey)
+	assert.ErrorIs(t, err, ErrDecrypt, "truncated final chunk")
+
+	// the first chunk alone is not a valid archive
+	first := len(encryptedMagic) + noncePrefixSize + 4 + chunkSize + 16
+	_, err = decrypt(encrypted[:first], testKey)
+	assert.ErrorIs(t, err, ErrDecrypt, "missing final chunk")
+
+	_, err = decrypt(append(encrypted, 0), testKey)
+	assert.ErrorIs(t, err, ErrDecrypt, "trailing data")
+
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)/2] ^= 1
+	_, err = decrypt(tampered, testKey)
+	assert.ErrorIs(t, err, ErrDecrypt, "tampered")
+}
@@ -0,0 +1,251 @@
+package backup
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// RestoreResult reports what a restore created.
+type RestoreResult struct {
+	Repos []*yuque.Book // repos created, in the order of the archive
+	Docs  int           // number of docs created
+
+	// Resumed lists the repos already in the group and the number of docs
+	// already in them, kept by a restore WithResume.
+	Resumed     []*yuque.Book
+	ResumedDocs int
+
+	// Skipped lists the docs that cannot be created through the API, such as
+	// sheets and boards, as namespace/slug of the archived repo.
+	Skipped []string
+}
+
+// restorer is the state of a restore.
+type restorer struct {
+	client *yuque.Client
+	group  string
+	result *RestoreResult
+
+	// repos maps the archived repo IDs to the created repos
+	repos map[int]*restoredRepo
+
+	// existing maps the slugs of the repos of the group to them, with resume only
+	existing map[string]*yuque.Book
+}
+
+// restoredRepo is a created repo, with the archived doc IDs mapped to the created ones.
+type restoredRepo struct {
+	book      *yuque.Book
+	namespace string
+	docs      map[int]int
+
+	// existing maps the slugs of the docs of a resumed repo to their IDs
+	existing map[string]int
+}
+
+// Restore recreates the repos of the archive in the group, with their docs
+// and TOC. Repos keep their slug, so the group must not have repos with the
+// same slugs.
+//
+// Restore stops at the first error, leaving the repos and docs created so far
+// in the group, as listed by the result returned with the error; nothing is
+// rolled back. Restore the archive again WithResume to continue from there.
+//
+// Docs are created from their lake body if any, keeping the formatting of
+// the original, and else from their HTML or Markdown body.
+//
+// group: 团队 ID 或 登录名(login)
+func Restore(ctx context.Context, client *yuque.Client, group string, r io.Reader, opts ...Option) (*RestoreResult, error) {
+	a, err := openArchive(r, newOptions(opts).key)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &restorer{
+		client: client,
+		group:  group,
+		result: &RestoreResult{},
+		repos:  make(map[int]*restoredRepo),
+	}
+	if newOptions(opts).resume {
+		rs.existing = make(map[string]*yuque.Book)
+		for book, err := range client.RepoService.AllGroupRepos(ctx, group, nil) {
+			if err != nil {
+				return rs.result, err
+			}
+			rs.existing[book.Slug] = book
+		}
+	}
+
+	for {
+		name, data, err := a.next()
+		if errors.Is(err, io.EOF) {
+			return rs.result, nil
+		}
+		if err != nil {
+			return rs.result, err
+		}
+
+		kind, repoID, _, ok := parseName(name)
+		if !ok {
+			continue
+		}
+		repo := rs.repos[repoID]
+		if kind != "repo" && repo == nil {
+			return rs.result, fmt.Errorf("backup: %s precedes its repo", name)
+		}
+
+		switch kind {
+		case "repo":
+			err = rs.restoreRepo(ctx, repoID, data)
+		case "doc":
+			err = rs.restoreDoc(ctx, repo, data)
+		case "toc":
+			err = rs.restoreTOC(ctx, repo, data)
+		}
+		if err != nil {
+			return rs.result, err
+		}
+	}
+}
+
+func (rs *restorer) restoreRepo(ctx context.Context, id int, data []byte) error {
+	var archived yuque.Book
+	if err := json.Unmarshal(data, &archived); err != nil {
+		return fmt.Errorf("backup: %s: %w", repoName(id), err)
+	}
+
+	if book, ok := rs.existing[archived.Slug]; ok {
+		return rs.resumeRepo(ctx, id, &archived, book)
+	}
+
+	book, _, err := rs.client.RepoService.CreateGroupRepo(ctx, rs.group, &yuque.CreateRepoRequest{
+		Name:        new(archived.Name),
+		Slug:        new(archived.Slug),
+		Description: new(archived.Description),
+		Public:      new(archived.Public),
+	})
+	if err != nil {
+		return fmt.Errorf("backup: create repo %s: %w", archived.Namespace, err)
+	}
+
+	rs.repos[id] = &restoredRepo{book: book, namespace: archived.Namespace, docs: make(map[int]int)}
+	rs.result.Repos = append(rs.result.Repos, book)
+	return nil
+}
+
+// resumeRepo keeps the repo of the group, with its docs.
+func (rs *restorer) resumeRepo(ctx context.Context, id int, archived, book *yuque.Book) error {
+	repo := &restoredRepo{book: book, namespace: archived.Namespace, docs: make(map[int]int), existing: make(map[string]int)}
+	for doc, err := range rs.client.DocService.AllDocs(ctx, book.ID, nil) {
+		if err != nil {
+			return fmt.Errorf("backup: docs of %s: %w", book.Namespace, err)
+		}
+		repo.existing[doc.Slug] = doc.ID
+	}
+
+	rs.repos[id] = repo
+	rs.result.Resumed = append(rs.result.Resumed, book)
+	return nil
+}
+
+func (rs *restorer) restoreDoc(ctx context.Context, repo *restoredRepo, data []byte) error {
+	var archived yuque.Doc
+	if err := json.Unmarshal(data, &archived); err != nil {
+		return fmt.Errorf("backup: doc of %s: %w", repo.namespace, err)
+	}
+
+	if archived.Type != "" && archived.Type != yuque.DocTypeDoc {
+		rs.result.Skipped = append(rs.result.Skipped, repo.namespace+"/"+archived.Slug)
+		return nil
+	}
+
+	if id, ok := repo.existing[archived.Slug]; ok {
+		repo.docs[archived.ID] = id
+		rs.result.ResumedDocs++
+		return nil
+	}
+
+	format, body := restoreBody(&archived)
+	doc, _, err := rs.client.DocService.CreateDoc(ctx, repo.book.ID, &yuque.CreateDocRequest{
+		Slug:   new(archived.Slug),
+		Title:  new(archived.Title),
+		Public: new(archived.Public),
+		Format: new(format),
+		Body:   new(body),
+	})
+	if err != nil {
+		return fmt.Errorf("backup: create doc %s/%s: %w", repo.namespace, archived.Slug, err)
+	}
+
+	repo.docs[archived.ID] = doc.ID
+	rs.result.Docs++
+	return nil
+}
+
+// restoreBody returns the most faithful body of the doc, with its format.
+func restoreBody(doc *yuque.Doc) (yuque.DocFormat, string) {
+	switch {
+	case doc.BodyLake != nil && *doc.BodyLake != "":
+		return yuque.DocFormatLake, *doc.BodyLake
+	case doc.Format != nil && *doc.Format == yuque.DocFormatHTML && doc.BodyHTML != nil:
+		return yuque.DocFormatHTML, *doc.BodyHTML
+	case doc.Body != nil:
+		return yuque.DocFormatMarkdown, *doc.Body
+	}
+	return yuque.DocFormatMarkdown, ""
+}
+
+func (rs *restorer) restoreTOC(ctx context.Context, repo *restoredRepo, data []byte) error {
+	var tocs []*yuque.TOC
+	if err := json.Unmarshal(data, &tocs); err != nil {
+		return fmt.Errorf("backup: toc of %s: %w", repo.namespace, err)
+	}
+
+	desired := desiredTOC(yuque.NewTOCTree(tocs).Roots(), repo.docs)
+	if _, _, err := rs.client.DocService.ReconcileTOC(ctx, repo.book.ID, &yuque.ReconcileTOCRequest{Desired: desired}); err != nil {
+		return fmt.Errorf("backup: restore toc of %s: %w", repo.namespace, err)
+	}
+	return nil
+}
+
+// desiredTOC returns the TOC of the nodes with the restored doc IDs; the
+// nodes of docs not restored are replaced by their children.
+func desiredTOC(nodes []*yuque.TOCNode, docs map[int]int) []*yuque.DesiredTOCNode {
+	var desired []*yuque.DesiredTOCNode
+	for _, node := range nodes {
+		children := desiredTOC(node.Children, docs)
+
+		d := &yuque.DesiredTOCNode{
+			Type:       node.Type,
+			Title:      node.Title,
+			OpenWindow: new(node.OpenWindow),
+			Visible:    new(node.Visible),
+			Children:   children,
+		}
+		switch node.Type {
+		case yuque.TOCTypeDoc:
+			id, ok := docs[cmp.Or(node.DocID, node.ID)]
+			if !ok {
+				desired = append(desired, children...)
+				continue
+			}
+			d.DocID = id
+		case yuque.TOCTypeLink:
+			d.URL = node.URL
+		case yuque.TOCTypeTitle:
+			// the title is all there is
+		default:
+			desired = append(desired, children...)
+			continue
+		}
+		desired = append(desired, d)
+	}
+	return desired
+}
@@ -15,8 +15,9 @@ import (
 )
 
 const (
-	defaultBaseURL   = "https://www.yuque.com/api/v2/"
-	defaultUserAgent = "go-yuque"
+	defaultBaseURL         = "https://www.yuque.com/api/v2/"
+	defaultUserAgent       = "go-yuque"
+	defaultMaxResponseSize = 64 << 20
 )
 
 var defaultHTTPClient = NewRetryableHTTPClient()
@@ -34,9 +35,21 @@ type Client struct {
 	// httpClient is the HTTP client used to communicate with the API.
 	httpClient *http.Client
 
+	// rateLimiter throttles requests before they are sent, nil disables it.
+	rateLimiter RateLimiter
+
+	// maxResponseSize is the maximum size of a response body in bytes, 0 disables the limit.
+	maxResponseSize int64
+
+	// keepResponseData keeps the raw data field for Response.Data when decoding into a target.
+	keepResponseData bool
+
 	// services used for talking to different parts of the Tapd API.
 	UserService      *userService
 	DocService       *docService
+	RepoService      *repoService
+	GroupService     *groupService
+	SearchService    *searchService
 	StatisticService *statisticService
 }
 
@@ -49,8 +62,10 @@ func NewClient(token string, opts ...ClientOption) (*Client, error) {
 // newClient returns a new Tapd API client.
 func newClient(opts ...ClientOption) (*Client, error) {
 	c := &Client{
-		userAgent:  defaultUserAgent,
-		httpClient: defaultHTTPClient,
+		userAgent:       defaultUserAgent,
+		httpClient:      defaultHTTPClient,
+		rateLimiter:     NewTokenBucketRateLimiter(defaultRateLimitInterval, defaultRateLimitBurst),
+		maxResponseSize: defaultMaxResponseSize,
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -66,6 +81,9 @@ func newClient(opts ...ClientOption) (*Client, error) {
 	// services
 	c.UserService = &userService{c}
 	c.DocService = &docService{c}
+	c.RepoService = &repoService{c}
+	c.GroupService = &groupService{c}
+	c.SearchService = &searchService{c}
 	c.StatisticService = &statisticService{c}
 
 	return c, nil
@@ -79,6 +97,10 @@ func (c *Client) setup() error {
 		}
 	}
 
+	if c.rateLimiter != nil {
+		c.httpClient = withRateLimitTransport(c.httpClient)
+	}
+
 	return nil
 }
 
@@ -161,6 +183,11 @@ func (c *Client) NewRequest(ctx context.Context, method, path string, data any,
 }
 
 func (c *Client) Do(req *http.Request, v any) (*Response, error) {
+	// each attempt waits for the limiter, see rateLimitTransport
+	if c.rateLimiter != nil {
+		req = withRateLimiter(req, c.rateLimiter)
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -168,26 +195,44 @@ func (c *Client) Do(req *http.Request, v any) (*Response, error) {
 	defer resp.Body.Close()              //nolint:errcheck
 	defer io.Copy(io.Discard, resp.Body) //nolint:errcheck
 
-	// decode response body
-	var rawBody RawBody
-	if err := json.NewDecoder(resp.Body).Decode(&rawBody); err != nil {
-		return nil, err
+	body := newMaxBytesReader(resp.Body, c.maxResponseSize)
+	if c.maxResponseSize > 0 && resp.ContentLength > c.maxResponseSize {
+		return nil, &ErrorResponse{response: resp, err: body.tooLarge()}
 	}
 
-	// check status
-	if resp.StatusCode != http.StatusOK {
-		return nil, &ErrorResponse{
+	contentType := resp.Header.Get("Content-Type")
+
+	// check status, error bodies are small and kept as a snippet
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, &ErrorResponse{response: resp, body: bodySnippet(data), err: err}
+		}
+
+		rawBody, decodeErr := decodeBody(contentType, int64(len(data)), bytes.NewReader(data), nil, true)
+		errResp := &ErrorResponse{
 			response: resp,
-			rawBody:  &rawBody,
-			err:      errors.New(rawBody.Message),
+			rawBody:  rawBody,
+			body:     bodySnippet(data),
 		}
-	}
 
-	if v != nil {
-		if err := json.Unmarshal(rawBody.Data, v); err != nil {
-			return nil, err
+		switch {
+		case rawBody != nil:
+			errResp.err = errors.New(errResp.Message())
+		case decodeErr != nil:
+			errResp.err = decodeErr
+		default:
+			errResp.err = errors.New(http.StatusText(resp.StatusCode))
 		}
+
+		return nil, errResp
+	}
+
+	// an empty body yields a nil raw body
+	rawBody, err := decodeBody(contentType, resp.ContentLength, body, v, c.keepResponseData)
+	if err != nil {
+		return nil, &ErrorResponse{response: resp, err: err}
 	}
 
-	return &Response{Response: resp, rawBody: &rawBody}, nil
+	return &Response{Response: resp, rawBody: rawBody}, nil
 }
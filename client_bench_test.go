@@ -0,0 +1,121 @@
+package yuque
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newBenchClient 返回一个直接从内存返回 body 的客户端, 排除网络开销
+//
+// chunked 为 true 时不返回 Content-Length, 模拟分块传输的响应
+func newBenchClient(b *testing.B, body []byte, chunked bool, opts ...ClientOption) *Client {
+	contentLength := int64(len(body))
+	if chunked {
+		contentLength = -1
+	}
+
+	client, err := NewClient(apiToken, append([]ClientOption{
+		WithRateLimiter(nil),
+		WithHTTPClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+				Body:          io.NopCloser(bytes.NewReader(body)),
+				ContentLength: contentLength,
+				Request:       req,
+			}, nil
+		})}),
+	}, opts...)...)
+	require.NoError(b, err)
+
+	return client
+}
+
+// largeDocFixture 基于 get_doc.json 构造一个正文约 5MB 的文档
+func largeDocFixture(b *testing.B) []byte {
+	var envelope struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(b, json.Unmarshal(loadData(b, "internal/testdata/api/doc/get_doc.json"), &envelope))
+
+	paragraph := strings.Repeat("这是文档正文内容, 包含 \"引号\" 与 <标签>。\n", 32)
+	body := strings.Repeat(paragraph, 5<<20/len(paragraph))
+	envelope.Data["body"] = body
+	envelope.Data["body_html"] = body
+	envelope.Data["body_lake"] = body
+
+	data, err := json.Marshal(envelope)
+	require.NoError(b, err)
+
+	return data
+}
+
+func benchmarkClientDo[T any](b *testing.B, body []byte, chunked bool, opts ...ClientOption) {
+	client := newBenchClient(b, body, chunked, opts...)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+
+	for b.Loop() {
+		req, err := client.NewRequest(ctx, http.MethodGet, "bench", nil, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var v T
+		if _, err := client.Do(req, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkClient_Do(b *testing.B) {
+	b.Run("get_doc", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, loadData(b, "internal/testdata/api/doc/get_doc.json"), false)
+	})
+	b.Run("get_docs", func(b *testing.B) {
+		benchmarkClientDo[[]*Doc](b, loadData(b, "internal/testdata/api/doc/get_docs.json"), false)
+	})
+	b.Run("get_tocs", func(b *testing.B) {
+		benchmarkClientDo[[]*rawTOC](b, loadData(b, "internal/testdata/api/doc/get_tocs.json"), false)
+	})
+	b.Run("get_repos", func(b *testing.B) {
+		benchmarkClientDo[[]*Book](b, loadData(b, "internal/testdata/api/repo/get_repos.json"), false)
+	})
+	b.Run("large_doc", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, largeDocFixture(b), false)
+	})
+	b.Run("large_doc_chunked", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, largeDocFixture(b), true)
+	})
+}
+
+// BenchmarkClient_Do_KeepData 对比默认的流式解码与 WithKeepResponseData 保留原始 data 的开销
+func BenchmarkClient_Do_KeepData(b *testing.B) {
+	body := largeDocFixture(b)
+
+	b.Run("stream", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, body, false)
+	})
+	b.Run("keep", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, body, false, WithKeepResponseData())
+	})
+	b.Run("stream_chunked", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, body, true)
+	})
+	b.Run("keep_chunked", func(b *testing.B) {
+		benchmarkClientDo[Doc](b, body, true, WithKeepResponseData())
+	})
+}
@@ -1,6 +1,9 @@
 package yuque
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 type ClientOption func(*Client) error
 
@@ -32,3 +35,41 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 		return nil
 	}
 }
+
+// WithRateLimiter sets the rate limiter for the client, nil disables rate limiting.
+//
+// The default is a token bucket allowing 100 requests per second. With a client
+// from NewRetryableHTTPClient, every retry attempt waits for the limiter too.
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *Client) error {
+		c.rateLimiter = limiter
+		return nil
+	}
+}
+
+// WithRateLimit sets a token bucket rate limiter allowing one request per interval with the given burst.
+func WithRateLimit(interval time.Duration, burst int) ClientOption {
+	return WithRateLimiter(NewTokenBucketRateLimiter(interval, burst))
+}
+
+// WithMaxResponseSize sets the maximum size of a response body in bytes, 0 disables the limit.
+//
+// The default is 64 MiB, larger responses fail with ErrResponseTooLarge.
+func WithMaxResponseSize(size int64) ClientOption {
+	return func(c *Client) error {
+		c.maxResponseSize = size
+		return nil
+	}
+}
+
+// WithKeepResponseData keeps the raw JSON of the data field for Response.Data
+// when Client.Do is called with a non-nil value.
+//
+// By default the data field is streamed straight into the value and not kept,
+// so that large responses are neither held twice in memory nor parsed twice.
+func WithKeepResponseData() ClientOption {
+	return func(c *Client) error {
+		c.keepResponseData = true
+		return nil
+	}
+}
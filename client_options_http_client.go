@@ -45,11 +45,24 @@ func WithRetryableHTTPClientBackoff(backoff retryablehttp.Backoff) RetryableHTTP
 	}
 }
 
+func WithRetryableHTTPClientTransport(transport http.RoundTripper) RetryableHTTPClientOption {
+	return func(client *retryablehttp.Client) {
+		client.HTTPClient.Transport = transport
+	}
+}
+
+// NewRetryableHTTPClient returns an HTTP client retrying failed requests.
+//
+// Once retries are exhausted the last response is returned as is, so that
+// Client.Do reports its status, such as ErrRateLimited or ErrServerError.
+// Each attempt waits for the rate limiter of the Client sending the request.
 func NewRetryableHTTPClient(opts ...RetryableHTTPClientOption) *http.Client {
 	retryClient := retryablehttp.NewClient()
 	retryClient.Logger = nil
+	retryClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
 	for _, opt := range opts {
 		opt(retryClient)
 	}
+	retryClient.HTTPClient.Transport = &rateLimitTransport{base: retryClient.HTTPClient.Transport}
 	return retryClient.StandardClient()
 }
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +36,8 @@ func newTestClient(t *testing.T, handler http.Handler) *Client {
 		WithBaseURL(srv.URL),
 		WithHTTPClient(NewRetryableHTTPClient(
 			WithRetryableHTTPClientLogger(log.New(os.Stderr, "", log.LstdFlags)),
+			WithRetryableHTTPClientRetryWaitMin(time.Millisecond),
+			WithRetryableHTTPClientRetryWaitMax(time.Millisecond),
 		)),
 	)
 	assert.NoError(t, err)
@@ -41,7 +45,7 @@ func newTestClient(t *testing.T, handler http.Handler) *Client {
 	return client
 }
 
-func loadData(t *testing.T, filepath string) []byte {
+func loadData(t testing.TB, filepath string) []byte {
 	content, err := os.ReadFile(filepath)
 	assert.NoError(t, err)
 	return content
@@ -160,3 +164,185 @@ func mustParseTime(t *testing.T, s string) time.Time {
 	require.NoError(t, err)
 	return ts
 }
+
+func TestClient_ResponseMetadata(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-Request-Id", "request-id")
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/get_repos.json"))
+	}))
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "users/yuque/repos", nil, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 28, resp.Total())
+	assert.Equal(t, "request-id", resp.RequestID())
+	assert.Equal(t, &RateLimit{Limit: 5000, Remaining: 4999}, resp.RateLimit())
+	assert.True(t, strings.HasPrefix(string(resp.Data()), "["))
+}
+
+func TestClient_ResponseData(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(loadData(t, "internal/testdata/api/repo/get_repos.json"))
+	}))
+
+	// streamed into the target by default
+	repos, resp, err := client.RepoService.GetUserRepos(ctx, "yuque", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, repos.Books)
+	assert.Nil(t, resp.Data())
+
+	require.NoError(t, WithKeepResponseData()(client))
+	repos, resp, err = client.RepoService.GetUserRepos(ctx, "yuque", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, repos.Books)
+	assert.True(t, strings.HasPrefix(string(resp.Data()), "["))
+}
+
+func TestClient_ErrorResponseNotFound(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "request-id")
+		w.WriteHeader(http.StatusNotFound)
+
+		fmt.Fprint(w, `{"status": 404, "message": "Not Found"}`) //nolint: errcheck
+	}))
+
+	_, _, err := client.DocService.GetDoc(ctx, "org/book", "missing")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrForbidden)
+
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusNotFound, errResp.StatusCode())
+	assert.Equal(t, "Not Found", errResp.Message())
+	assert.Equal(t, "request-id", errResp.RequestID())
+	assert.EqualError(t, errResp.Unwrap(), "Not Found")
+}
+
+// newPlainTestClient 返回不重试的客户端, 用于 5xx 等会触发重试的响应
+func newPlainTestClient(t *testing.T, handler http.Handler) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(apiToken, WithBaseURL(srv.URL), WithHTTPClient(srv.Client()))
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestClient_ErrorResponseNonJSON(t *testing.T) {
+	client := newPlainTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+
+		fmt.Fprint(w, "<html><body>502 Bad Gateway</body></html>"+strings.Repeat(" ", 4096)) //nolint: errcheck
+	}))
+
+	_, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrServerError)
+	assert.ErrorContains(t, err, "status code: 502")
+	assert.ErrorContains(t, err, "non-JSON response body (text/html)")
+	assert.NotContains(t, err.Error(), "invalid character")
+
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadGateway, errResp.StatusCode())
+	assert.Equal(t, "<html><body>502 Bad Gateway</body></html>", string(errResp.Body()))
+}
+
+func TestClient_ErrorResponseAfterRetries(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{name: "502 gateway page", status: http.StatusBadGateway, body: "<html><body>502 Bad Gateway</body></html>", want: ErrServerError},
+		{name: "429", status: http.StatusTooManyRequests, body: `{"status": 429, "message": "too many requests"}`, want: ErrRateLimited},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body) //nolint: errcheck
+			}))
+
+			_, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.want)
+			assert.NotContains(t, err.Error(), "giving up")
+			assert.EqualValues(t, 5, attempts.Load(), "retried before giving up")
+
+			var errResp *ErrorResponse
+			require.ErrorAs(t, err, &errResp)
+			assert.Equal(t, tt.status, errResp.StatusCode())
+			assert.Equal(t, tt.body, string(errResp.Body()))
+		})
+	}
+}
+
+func TestClient_ErrorResponseBodySnippet(t *testing.T) {
+	client := newPlainTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		fmt.Fprint(w, strings.Repeat("x", 4096)) //nolint: errcheck
+	}))
+
+	_, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Len(t, errResp.Body(), maxBodySnippet)
+	assert.Less(t, len(err.Error()), 2*maxBodySnippet)
+}
+
+func TestClient_ErrorResponseEmptyBody(t *testing.T) {
+	client := newPlainTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	_, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrServerError)
+	assert.EqualError(t, err, "status code: 500, err: Internal Server Error")
+
+	var errResp *ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Empty(t, errResp.Body())
+	assert.Zero(t, errResp.Code())
+}
+
+func TestClient_ErrorResponseInvalidJSON(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		fmt.Fprint(w, `{"data": `) //nolint: errcheck
+	}))
+
+	_, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "status code: 200")
+	assert.ErrorContains(t, err, "invalid JSON response body")
+}
+
+func TestClient_EmptyBody(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	doc, resp, err := client.DocService.DeleteDoc(ctx, "org/book", 1)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Zero(t, resp.Total())
+	assert.Nil(t, resp.Data())
+	assert.NotNil(t, doc)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// apiResponse is the output of the api command.
+type apiResponse struct {
+	Data json.RawMessage `json:"data"`
+	Meta *yuque.Meta     `json:"meta,omitempty"`
+}
+
+// keyValues is a repeatable key=value flag.
+type keyValues [][2]string
+
+func (kv *keyValues) String() string {
+	return fmt.Sprint(*kv)
+}
+
+func (kv *keyValues) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%q is not key=value", s)
+	}
+	*kv = append(*kv, [2]string{key, value})
+	return nil
+}
+
+// headers is a repeatable "Name: value" flag.
+type headers http.Header
+
+func (h headers) String() string {
+	return fmt.Sprint(http.Header(h))
+}
+
+func (h headers) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%q is not name:value", s)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+func setupAPI(fs *flag.FlagSet) runFunc {
+	var rawFields, typedFields keyValues
+	fs.Var(&rawFields, "f", "add a string field `key=value`, repeatable")
+	fs.Var(&typedFields, "F", "add a JSON typed field `key=value`, such as limit=10 or public=true, repeatable")
+	header := headers{}
+	fs.Var(header, "H", "add a request header `name:value`, repeatable")
+	input := fs.String("input", "", "file of the JSON request body, - reads stdin")
+
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		method := strings.ToUpper(args[0])
+
+		// the path is relative to the base URL, its query is merged with the fields
+		path, rawQuery, _ := strings.Cut(strings.TrimPrefix(args[1], "/"), "?")
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]any)
+		for _, kv := range rawFields {
+			fields[kv[0]] = kv[1]
+		}
+		for _, kv := range typedFields {
+			var v any
+			if err := json.Unmarshal([]byte(kv[1]), &v); err != nil {
+				v = kv[1]
+			}
+			fields[kv[0]] = v
+		}
+
+		var body any
+		switch method {
+		case http.MethodGet, http.MethodDelete:
+			if *input != "" {
+				return nil, fmt.Errorf("-input is not allowed for %s", method)
+			}
+			for key, value := range fields {
+				query.Add(key, fmt.Sprint(value))
+			}
+		case http.MethodPost, http.MethodPut:
+			if *input != "" {
+				if len(fields) > 0 {
+					return nil, errors.New("-input and fields are mutually exclusive")
+				}
+				data, err := a.readFile(*input)
+				if err != nil {
+					return nil, err
+				}
+				if !json.Valid(data) {
+					return nil, errors.New("-input is not valid JSON")
+				}
+				body = json.RawMessage(data)
+			} else {
+				body = fields
+			}
+		default:
+			return nil, fmt.Errorf("unsupported method %s, expected GET, POST, PUT or DELETE", method)
+		}
+
+		opts := []yuque.RequestOption{
+			yuque.WithRequestQuery(query),
+			yuque.WithRequestHeaderFunc(func(h http.Header) {
+				for name, values := range header {
+					h[name] = values
+				}
+			}),
+		}
+
+		var (
+			data *json.RawMessage
+			resp *yuque.Response
+		)
+		switch method {
+		case http.MethodGet:
+			data, resp, err = yuque.Get[json.RawMessage](ctx, a.client, path, nil, opts...)
+		case http.MethodPost:
+			data, resp, err = yuque.Post[json.RawMessage](ctx, a.client, path, body, opts...)
+		case http.MethodPut:
+			data, resp, err = yuque.Put[json.RawMessage](ctx, a.client, path, body, opts...)
+		case http.MethodDelete:
+			data, resp, err = yuque.Delete[json.RawMessage](ctx, a.client, path, opts...)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		res := &result{value: &apiResponse{Data: *data, Meta: resp.Meta()}}
+		if len(*data) > 0 {
+			indented, err := json.MarshalIndent(*data, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			res.body = string(indented)
+		}
+		return res, nil
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+func TestAPI(t *testing.T) {
+	c := newCLI(t)
+
+	// create with typed fields
+	var created apiResponse
+	require.NoError(t, json.Unmarshal([]byte(c.ok("api", "-f", "name=Guide", "-f", "slug=guide", "-F", "public=1", "POST", "users/yuque/repos")), &created))
+
+	var book yuque.Book
+	require.NoError(t, json.Unmarshal(created.Data, &book))
+	assert.Equal(t, "yuque/guide", book.Namespace)
+	assert.Equal(t, yuque.AccessTypePublic, book.Public)
+	assert.Nil(t, created.Meta)
+
+	// create from a JSON body
+	code, _, stderr := c.run(`{"slug":"intro","title":"入门","body":"# 入门"}`, "api", "-input", "-", "post", "/repos/yuque/guide/docs")
+	require.Equal(t, 0, code, stderr)
+
+	// list with meta, the query of the path is merged with the fields
+	var list apiResponse
+	require.NoError(t, json.Unmarshal([]byte(c.ok("api", "-F", "limit=1", "GET", "repos/yuque/guide/docs?offset=0")), &list))
+	require.NotNil(t, list.Meta)
+	assert.Equal(t, 1, list.Meta.Total)
+
+	var docs []*yuque.Doc
+	require.NoError(t, json.Unmarshal(list.Data, &docs))
+	require.Len(t, docs, 1)
+	assert.Equal(t, "intro", docs[0].Slug)
+
+	// -o body prints the data only
+	var doc yuque.Doc
+	require.NoError(t, json.Unmarshal([]byte(c.ok("-o", "body", "api", "GET", "repos/yuque/guide/docs/intro")), &doc))
+	assert.Equal(t, "入门", doc.Title)
+
+	c.ok("api", "-f", "title=开始", "PUT", "repos/yuque/guide/docs/intro")
+	c.ok("api", "-H", "X-Test: 1", "DELETE", "repos/yuque/guide/docs/intro")
+
+	code, _, stderr = c.run("", "api", "GET", "repos/yuque/guide/docs/intro")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "404")
+}
+
+func TestAPI_Invalid(t *testing.T) {
+	c := newCLI(t)
+
+	code, _, stderr := c.run("", "api", "PATCH", "user")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unsupported method PATCH")
+
+	code, _, stderr = c.run("{}", "api", "-input", "-", "GET", "user")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "-input is not allowed for GET")
+
+	code, _, stderr = c.run("not json", "api", "-input", "-", "POST", "users/yuque/repos")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "not valid JSON")
+
+	code, _, stderr = c.run("", "api", "-f", "name", "GET", "user")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `"name" is not key=value`)
+}
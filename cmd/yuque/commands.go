@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// runFunc runs a command with its positional arguments.
+type runFunc func(ctx context.Context, a *app, args []string) (*result, error)
+
+// command is a subcommand, named by one or more words such as "repos ls".
+type command struct {
+	name  string
+	usage string
+	short string
+	args  int
+
+	// setup registers the flags of the command and returns its run function.
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+var commands = []*command{
+	{
+		name:  "whoami",
+		usage: "whoami",
+		short: "show the authenticated user",
+		setup: func(*flag.FlagSet) runFunc { return runWhoami },
+	},
+	{
+		name:  "repos ls",
+		usage: "repos ls [-user login | -group login]",
+		short: "list the repos of a user or group, the authenticated user by default",
+		setup: setupReposList,
+	},
+	{
+		name:  "docs ls",
+		usage: "docs ls <namespace>",
+		short: "list the docs of a repo",
+		args:  1,
+		setup: func(*flag.FlagSet) runFunc { return runDocsList },
+	},
+	{
+		name:  "doc get",
+		usage: "doc get <namespace> <slug>",
+		short: "show a doc, -o body prints its body",
+		args:  2,
+		setup: func(*flag.FlagSet) runFunc { return runDocGet },
+	},
+	{
+		name:  "doc create",
+		usage: "doc create [flags] <namespace>",
+		short: "create a doc, the body is read from -file or stdin",
+		args:  1,
+		setup: setupDocCreate,
+	},
+	{
+		name:  "toc show",
+		usage: "toc show <namespace>",
+		short: "show the TOC of a repo as a tree",
+		args:  1,
+		setup: func(*flag.FlagSet) runFunc { return runTOCShow },
+	},
+	{
+		name:  "stats group",
+		usage: "stats group <login>",
+		short: "show the statistics of a group",
+		args:  1,
+		setup: func(*flag.FlagSet) runFunc { return runStatsGroup },
+	},
+	{
+		name:  "api",
+		usage: "api [flags] <method> <path>",
+		short: "send a request to any API path, printing its data and meta",
+		args:  2,
+		setup: setupAPI,
+	},
+	{
+		name:  "help",
+		usage: "help",
+		short: "show this help",
+	},
+}
+
+func runWhoami(ctx context.Context, a *app, _ []string) (*result, error) {
+	user, _, err := a.client.UserService.GetUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{
+		value: user,
+		rows: [][]string{
+			{"ID", strconv.Itoa(user.ID)},
+			{"LOGIN", user.Login},
+			{"NAME", user.Name},
+		},
+		body: user.Login,
+	}, nil
+}
+
+func setupReposList(fs *flag.FlagSet) runFunc {
+	user := fs.String("user", "", "login of the user")
+	group := fs.String("group", "", "login of the group")
+
+	return func(ctx context.Context, a *app, _ []string) (*result, error) {
+		if *user != "" && *group != "" {
+			return nil, errors.New("-user and -group are mutually exclusive")
+		}
+
+		var repos iter.Seq2[*yuque.Book, error]
+		switch {
+		case *group != "":
+			repos = a.client.RepoService.AllGroupRepos(ctx, *group, nil)
+		case *user != "":
+			repos = a.client.RepoService.AllUserRepos(ctx, *user, nil)
+		default:
+			me, _, err := a.client.UserService.GetUser(ctx)
+			if err != nil {
+				return nil, err
+			}
+			repos = a.client.RepoService.AllUserRepos(ctx, me.Login, nil)
+		}
+
+		books := []*yuque.Book{}
+		res := &result{header: []string{"ID", "NAMESPACE", "NAME", "TYPE", "PUBLIC", "DOCS", "UPDATED"}}
+
+		var body strings.Builder
+		for book, err := range repos {
+			if err != nil {
+				return nil, err
+			}
+
+			books = append(books, book)
+			res.rows = append(res.rows, []string{
+				strconv.Itoa(book.ID),
+				book.Namespace,
+				book.Name,
+				string(book.Type),
+				formatAccess(book.Public),
+				strconv.Itoa(book.ItemsCount),
+				formatTime(book.ContentUpdatedAt),
+			})
+			fmt.Fprintln(&body, book.Namespace)
+		}
+		res.value, res.body = books, body.String()
+
+		return res, nil
+	}
+}
+
+func runDocsList(ctx context.Context, a *app, args []string) (*result, error) {
+	docs := []*yuque.Doc{}
+	res := &result{header: []string{"ID", "SLUG", "TITLE", "PUBLIC", "WORDS", "UPDATED"}}
+
+	var body strings.Builder
+	for doc, err := range a.client.DocService.AllDocs(ctx, args[0], nil) {
+		if err != nil {
+			return nil, err
+		}
+
+		docs = append(docs, doc)
+		res.rows = append(res.rows, []string{
+			strconv.Itoa(doc.ID),
+			doc.Slug,
+			doc.Title,
+			formatAccess(doc.Public),
+			strconv.Itoa(doc.WordCount),
+			formatTime(doc.ContentUpdatedAt),
+		})
+		fmt.Fprintln(&body, doc.Slug)
+	}
+	res.value, res.body = docs, body.String()
+
+	return res, nil
+}
+
+func runDocGet(ctx context.Context, a *app, args []string) (*result, error) {
+	doc, _, err := a.client.DocService.GetDoc(ctx, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return docResult(doc), nil
+}
+
+func setupDocCreate(fs *flag.FlagSet) runFunc {
+	title := fs.String("title", "", "title of the doc")
+	slug := fs.String("slug", "", "slug of the doc, generated if empty")
+	format := fs.String("format", string(yuque.DocFormatMarkdown), "format of the body: markdown, html or lake")
+	file := fs.String("file", "-", "file of the body, - reads stdin")
+
+	return func(ctx context.Context, a *app, args []string) (*result, error) {
+		body, err := a.readFile(*file)
+		if err != nil {
+			return nil, err
+		}
+
+		request := &yuque.CreateDocRequest{
+			Format: new(yuque.DocFormat(*format)),
+			Body:   new(string(body)),
+		}
+		if *title != "" {
+			request.Title = title
+		}
+		if *slug != "" {
+			request.Slug = slug
+		}
+
+		doc, _, err := a.client.DocService.CreateDoc(ctx, args[0], request)
+		if err != nil {
+			return nil, err
+		}
+		return docResult(doc), nil
+	}
+}
+
+// readFile reads the named file, or stdin for "-".
+func (a *app) readFile(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(a.stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func docResult(doc *yuque.Doc) *result {
+	res := &result{
+		value: doc,
+		rows: [][]string{
+			{"ID", strconv.Itoa(doc.ID)},
+			{"SLUG", doc.Slug},
+			{"TITLE", doc.Title},
+			{"PUBLIC", formatAccess(doc.Public)},
+			{"WORDS", strconv.Itoa(doc.WordCount)},
+			{"UPDATED", formatTime(doc.ContentUpdatedAt)},
+		},
+	}
+	if doc.Format != nil {
+		res.rows = append(res.rows, []string{"FORMAT", string(*doc.Format)})
+	}
+	if doc.Body != nil {
+		res.body = *doc.Body
+	}
+	return res
+}
+
+func runTOCShow(ctx context.Context, a *app, args []string) (*result, error) {
+	tocs, _, err := a.client.DocService.GetTOCs(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	res := &result{value: tocs, header: []string{"TITLE", "TYPE", "URL"}}
+	var body strings.Builder
+	for node := range yuque.NewTOCTree(tocs).All() {
+		title := strings.Repeat("  ", node.TreeDepth()) + node.Title
+		res.rows = append(res.rows, []string{title, string(node.Type), node.URL})
+		fmt.Fprintln(&body, title)
+	}
+	res.body = body.String()
+
+	return res, nil
+}
+
+func runStatsGroup(ctx context.Context, a *app, args []string) (*result, error) {
+	stats, _, err := a.client.StatisticService.GetGroupStatistics(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &result{
+		value: stats,
+		rows: [][]string{
+			{"DATE", stats.BizDate},
+			{"MEMBERS", strconv.Itoa(stats.MemberCount)},
+			{"BOOKS", strconv.Itoa(stats.BookCount)},
+			{"PUBLIC BOOKS", strconv.Itoa(stats.PublicBookCount)},
+			{"PRIVATE BOOKS", strconv.Itoa(stats.PrivateBookCount)},
+			{"DOCS", strconv.Itoa(stats.DocCount)},
+			{"WRITES", strconv.Itoa(stats.WriteCount)},
+			{"READS", strconv.Itoa(stats.ReadCount)},
+			{"COMMENTS", strconv.Itoa(stats.CommentCount)},
+			{"LIKES", strconv.Itoa(stats.LikeCount)},
+		},
+	}, nil
+}
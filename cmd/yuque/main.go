@@ -0,0 +1,185 @@
+// Command yuque is a command line client of the Yuque API.
+//
+// Usage:
+//
+//	yuque [-token token] [-base-url url] [-o table|json|body] <command> [arguments]
+//
+// The token defaults to the YUQUE_TOKEN environment variable, and the base
+// URL to YUQUE_BASE_URL. Run yuque help for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+const (
+	envToken   = "YUQUE_TOKEN"
+	envBaseURL = "YUQUE_BASE_URL"
+)
+
+// errUsage reports invalid arguments, the usage has already been printed.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv))
+}
+
+// app is the state of a command line invocation.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	token   string
+	baseURL string
+	output  string
+
+	client *yuque.Client
+}
+
+// run runs the command line and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, getenv: getenv}
+
+	err := a.run(ctx, args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "yuque: %v\n", err)
+		return 1
+	}
+}
+
+func (a *app) run(ctx context.Context, args []string) error {
+	fs := a.flagSet("yuque")
+	fs.StringVar(&a.token, "token", "", "API token, defaults to $"+envToken)
+	fs.StringVar(&a.baseURL, "base-url", "", "API base URL, defaults to $"+envBaseURL)
+	fs.Usage = func() { a.usage(fs) }
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if a.token == "" {
+		a.token = a.getenv(envToken)
+	}
+	if a.baseURL == "" {
+		a.baseURL = a.getenv(envBaseURL)
+	}
+
+	cmd, args := lookupCommand(fs.Args())
+	if cmd == nil {
+		fs.Usage()
+		return errUsage
+	}
+	if cmd.name == "help" {
+		fs.SetOutput(a.stdout)
+		fs.Usage()
+		return nil
+	}
+
+	cfs := a.flagSet("yuque " + cmd.name)
+	run := cmd.setup(cfs)
+	cfs.Usage = func() {
+		fmt.Fprintf(cfs.Output(), "Usage: yuque %s\n\n%s\n", cmd.usage, cmd.short)
+		cfs.PrintDefaults()
+	}
+	if err := parseFlags(cfs, args); err != nil {
+		return err
+	}
+	if cfs.NArg() != cmd.args {
+		cfs.Usage()
+		return errUsage
+	}
+
+	if err := a.setupClient(); err != nil {
+		return err
+	}
+
+	res, err := run(ctx, a, cfs.Args())
+	if err != nil {
+		return err
+	}
+	return a.print(res)
+}
+
+// parseFlags parses the flags, the flag set has printed the error and usage if invalid.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
+
+// flagSet returns a flag set with the output flag, which is accepted before and after the command.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Func("o", "output format: table, json or body (default table)", func(s string) error {
+		switch s {
+		case outputTable, outputJSON, outputBody:
+			a.output = s
+			return nil
+		}
+		return fmt.Errorf("unknown output format %q", s)
+	})
+	return fs
+}
+
+func (a *app) usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: yuque [flags] <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-40s %s\n", cmd.usage, cmd.short)
+	}
+	fmt.Fprintf(w, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+func (a *app) setupClient() error {
+	if a.token == "" {
+		return fmt.Errorf("missing token, set -token or $%s", envToken)
+	}
+
+	var opts []yuque.ClientOption
+	if a.baseURL != "" {
+		opts = append(opts, yuque.WithBaseURL(a.baseURL))
+	}
+
+	client, err := yuque.NewClient(a.token, opts...)
+	if err != nil {
+		return err
+	}
+	a.client = client
+
+	return nil
+}
+
+// lookupCommand returns the command named by the leading arguments, and the remaining arguments.
+func lookupCommand(args []string) (*command, []string) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return cmd, args[len(words):]
+		}
+	}
+	return nil, args
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+	"github.com/flc1125/go-yuque/yuquetest"
+)
+
+var ctx = context.Background()
+
+// cli runs the command line against a fake server.
+type cli struct {
+	t   *testing.T
+	srv *yuquetest.Server
+	env map[string]string
+}
+
+func newCLI(t *testing.T) *cli {
+	srv := yuquetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	return &cli{t: t, srv: srv, env: map[string]string{
+		envToken:   yuquetest.Token,
+		envBaseURL: srv.URL,
+	}}
+}
+
+func (c *cli) run(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(ctx, args, strings.NewReader(stdin), &out, &errOut, func(key string) string { return c.env[key] })
+	return code, out.String(), errOut.String()
+}
+
+// ok runs the command line, requiring it to succeed, and returns its output.
+func (c *cli) ok(args ...string) string {
+	code, stdout, stderr := c.run("", args...)
+	require.Equal(c.t, 0, code, stderr)
+	return stdout
+}
+
+func (c *cli) client() *yuque.Client {
+	client, err := c.srv.NewClient(yuquetest.Token)
+	require.NoError(c.t, err)
+	return client
+}
+
+func TestWhoami(t *testing.T) {
+	c := newCLI(t)
+
+	assert.Contains(t, c.ok("whoami"), "LOGIN  yuque")
+	assert.Equal(t, "yuque\n", c.ok("-o", "body", "whoami"))
+
+	var user yuque.User
+	require.NoError(t, json.Unmarshal([]byte(c.ok("whoami", "-o", "json")), &user))
+	assert.Equal(t, yuquetest.Login, user.Login)
+}
+
+func TestToken(t *testing.T) {
+	c := newCLI(t)
+	delete(c.env, envToken)
+
+	code, _, stderr := c.run("", "whoami")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing token")
+
+	assert.Equal(t, "yuque\n", c.ok("-token", yuquetest.Token, "-o", "body", "whoami"))
+
+	code, _, stderr = c.run("", "-token", "invalid", "whoami")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "401")
+}
+
+func TestUsage(t *testing.T) {
+	c := newCLI(t)
+
+	assert.Contains(t, c.ok("help"), "docs ls <namespace>")
+
+	code, _, stderr := c.run("", "unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: yuque")
+
+	code, _, stderr = c.run("", "doc", "get", "yuque/guide")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: yuque doc get <namespace> <slug>")
+
+	code, _, stderr = c.run("", "-o", "yaml", "whoami")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown output format "yaml"`)
+}
+
+func TestRepos(t *testing.T) {
+	c := newCLI(t)
+	client := c.client()
+
+	for _, slug := range []string{"guide", "notes"} {
+		_, _, err := client.RepoService.CreateUserRepo(ctx, yuquetest.Login, &yuque.CreateRepoRequest{Name: new(slug), Slug: new(slug)})
+		require.NoError(t, err)
+	}
+
+	group := c.srv.AddGroup("team", "Team", 1)
+	_, _, err := client.RepoService.CreateGroupRepo(ctx, group.Login, &yuque.CreateRepoRequest{Name: new("Wiki"), Slug: new("wiki")})
+	require.NoError(t, err)
+
+	out := c.ok("repos", "ls")
+	assert.Contains(t, out, "NAMESPACE")
+	assert.Contains(t, out, "yuque/guide")
+	assert.NotContains(t, out, "team/wiki")
+
+	assert.Equal(t, "yuque/guide\nyuque/notes\n", c.ok("-o", "body", "repos", "ls", "-user", "yuque"))
+	assert.Equal(t, "team/wiki\n", c.ok("-o", "body", "repos", "ls", "-group", "team"))
+}
+
+func TestDocs(t *testing.T) {
+	c := newCLI(t)
+	_, _, err := c.client().RepoService.CreateUserRepo(ctx, yuquetest.Login, &yuque.CreateRepoRequest{Name: new("Guide"), Slug: new("guide")})
+	require.NoError(t, err)
+
+	code, stdout, stderr := c.run("# 入门\n", "doc", "create", "-title", "入门", "-slug", "intro", "yuque/guide")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "SLUG     intro")
+
+	file := filepath.Join(t.TempDir(), "faq.md")
+	require.NoError(t, os.WriteFile(file, []byte("# FAQ"), 0o600))
+	c.ok("doc", "create", "-slug", "faq", "-file", file, "yuque/guide")
+
+	assert.Equal(t, "intro\nfaq\n", c.ok("-o", "body", "docs", "ls", "yuque/guide"))
+	assert.Contains(t, c.ok("docs", "ls", "yuque/guide"), "入门")
+
+	assert.Equal(t, "# 入门\n", c.ok("-o", "body", "doc", "get", "yuque/guide", "intro"))
+	assert.Equal(t, "# FAQ\n", c.ok("doc", "get", "-o", "body", "yuque/guide", "faq"))
+
+	code, _, stderr = c.run("", "doc", "get", "yuque/guide", "missing")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "404")
+}
+
+func TestTOCShow(t *testing.T) {
+	c := newCLI(t)
+	client := c.client()
+
+	book, _, err := client.RepoService.CreateUserRepo(ctx, yuquetest.Login, &yuque.CreateRepoRequest{Name: new("Guide"), Slug: new("guide")})
+	require.NoError(t, err)
+	doc, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{Title: new("入门")})
+	require.NoError(t, err)
+
+	tocs, _, err := client.DocService.UpdateTOC(ctx, book.ID, &yuque.UpdateTOCRequest{
+		Action:     new(yuque.TOCActionAppendNode),
+		ActionMode: new(yuque.TOCActionModeChild),
+		Type:       new(yuque.TOCTypeTitle),
+		Title:      new("开始"),
+	})
+	require.NoError(t, err)
+	_, _, err = client.DocService.UpdateTOC(ctx, book.ID, &yuque.UpdateTOCRequest{
+		Action:     new(yuque.TOCActionAppendNode),
+		ActionMode: new(yuque.TOCActionModeChild),
+		TargetUUID: new(tocs[0].UUID),
+		Type:       new(yuque.TOCTypeDoc),
+		DocIDs:     []int{doc.ID},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "开始\n  入门\n", c.ok("-o", "body", "toc", "show", "yuque/guide"))
+	assert.Contains(t, c.ok("toc", "show", "yuque/guide"), "TITLE")
+}
+
+func TestStatsGroup(t *testing.T) {
+	c := newCLI(t)
+	c.srv.AddGroup("team", "Team", 1)
+
+	out := c.ok("stats", "group", "team")
+	assert.Contains(t, out, "MEMBERS")
+
+	var stats yuque.GetGroupStatisticsResponse
+	require.NoError(t, json.Unmarshal([]byte(c.ok("-o", "json", "stats", "group", "team")), &stats))
+	assert.Equal(t, 1, stats.MemberCount)
+}
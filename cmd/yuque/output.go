@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/flc1125/go-yuque"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputBody  = "body"
+)
+
+// result is the output of a command, rendered in the selected output format.
+type result struct {
+	// value is printed as indented JSON.
+	value any
+
+	// header and rows are printed as a table, rows without a header are key value pairs.
+	// A result without rows is printed as JSON instead.
+	header []string
+	rows   [][]string
+
+	// body is printed as is, rows are printed tab separated without the header if empty.
+	body string
+}
+
+func (a *app) print(res *result) error {
+	output := a.output
+	if res.header == nil && res.rows == nil && output != outputBody {
+		output = outputJSON
+	}
+
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(res.value)
+
+	case outputBody:
+		if res.body != "" {
+			_, err := fmt.Fprint(a.stdout, ensureNewline(res.body))
+			return err
+		}
+		for _, row := range res.rows {
+			if _, err := fmt.Fprintln(a.stdout, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		tw := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+		if len(res.header) > 0 {
+			fmt.Fprintln(tw, strings.Join(res.header, "\t"))
+		}
+		for _, row := range res.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+func ensureNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.DateTime)
+}
+
+func formatAccess(public yuque.AccessType) string {
+	switch public {
+	case yuque.AccessTypePrivate:
+		return "private"
+	case yuque.AccessTypePublic:
+		return "public"
+	case yuque.AccessTypeInner:
+		return "inner"
+	}
+	return fmt.Sprint(int(public))
+}
@@ -0,0 +1,148 @@
+package yuque
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the maximum response size.
+var ErrResponseTooLarge = errors.New("yuque: response body too large")
+
+// maxBodySnippet is the maximum number of bytes of the raw body kept in an ErrorResponse.
+const maxBodySnippet = 1 << 10
+
+// decodeBody decodes the response envelope according to its content type,
+// decoding the data field straight into v instead of buffering it as raw JSON
+// and unmarshalling it a second time. The data field is kept as raw JSON only
+// when v is nil, or with keepData, in which case v is unmarshalled from it. An
+// empty body yields a nil raw body.
+//
+// The body is streamed through a json.Decoder, limited to size bytes when known
+// (size >= 0), so that a large Content-Length is never trusted for allocation.
+//
+// Bodies declared as JSON must be valid JSON; bodies without a content type or
+// with text/plain are decoded on a best-effort basis; any other content type,
+// such as an HTML page from a gateway, is reported as a non-JSON body.
+func decodeBody(contentType string, size int64, r io.Reader, v any, keepData bool) (*RawBody, error) {
+	br := bufio.NewReader(r)
+
+	first, err := peekNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	isJSON := strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "+json")
+
+	if !isJSON && (first != '{' || (mediaType != "" && mediaType != "text/plain")) {
+		snippet, _ := io.ReadAll(io.LimitReader(br, maxBodySnippet))
+		if mediaType == "" {
+			return nil, fmt.Errorf("non-JSON response body: %s", bodySnippet(snippet))
+		}
+		return nil, fmt.Errorf("non-JSON response body (%s): %s", mediaType, bodySnippet(snippet))
+	}
+
+	rawBody := &RawBody{}
+	env := &envelope{RawBody: rawBody, Data: &rawBody.Data}
+	if v != nil && !keepData {
+		env.Data = v
+	}
+
+	var body io.Reader = br
+	if size >= 0 {
+		body = io.LimitReader(br, size)
+	}
+	err = json.NewDecoder(body).Decode(env)
+	if err == nil && v != nil && keepData && len(rawBody.Data) > 0 {
+		err = json.Unmarshal(rawBody.Data, v)
+	}
+
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("invalid JSON response body: %w", err)
+		}
+		return nil, err
+	}
+
+	return rawBody, nil
+}
+
+// envelope is the response body, with the data field decoded into the value
+// held by Data, which must be a non-nil pointer.
+type envelope struct {
+	*RawBody
+	Data any `json:"data"`
+}
+
+// peekNonSpace skips leading whitespace and returns the next byte without consuming it.
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+
+		return c, br.UnreadByte()
+	}
+}
+
+// bodySnippet returns the leading bytes of the body, capped at maxBodySnippet.
+func bodySnippet(body []byte) []byte {
+	body = bytes.TrimSpace(body)
+	if len(body) > maxBodySnippet {
+		body = body[:maxBodySnippet]
+	}
+	return bytes.Clone(body)
+}
+
+// maxBytesReader reads from r, failing with ErrResponseTooLarge once more than max bytes are read.
+type maxBytesReader struct {
+	r         io.Reader
+	max       int64
+	remaining int64
+}
+
+// newMaxBytesReader returns a reader limited to max bytes, max <= 0 disables the limit.
+func newMaxBytesReader(r io.Reader, maxBytes int64) *maxBytesReader {
+	return &maxBytesReader{r: r, max: maxBytes, remaining: maxBytes}
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.max <= 0 {
+		return m.r.Read(p)
+	}
+
+	// read one byte past the limit to tell an exact fit from an overflow
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+
+	n, err := m.r.Read(p)
+	if int64(n) > m.remaining {
+		n = int(m.remaining)
+		m.remaining = 0
+		return n, m.tooLarge()
+	}
+	m.remaining -= int64(n)
+
+	return n, err
+}
+
+func (m *maxBytesReader) tooLarge() error {
+	return fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, m.max)
+}
@@ -0,0 +1,101 @@
+package yuque
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBody(t *testing.T) {
+	const body = `{"data": {"id": 1, "title": "文档"}, "meta": {"total": 2}, "status": 200, "unknown": [1, 2]}`
+
+	for _, size := range []int64{int64(len(body)), -1} {
+		t.Run(fmt.Sprintf("size %d", size), func(t *testing.T) {
+			var doc Doc
+			rawBody, err := decodeBody("application/json", size, iotest.OneByteReader(strings.NewReader(body)), &doc, true)
+			require.NoError(t, err)
+			assert.Equal(t, 1, doc.ID)
+			assert.Equal(t, "文档", doc.Title)
+			assert.Equal(t, &Meta{Total: 2}, rawBody.Meta)
+			assert.Equal(t, 200, rawBody.Status)
+			assert.JSONEq(t, `{"id": 1, "title": "文档"}`, string(rawBody.Data))
+
+			doc = Doc{}
+			rawBody, err = decodeBody("application/json", size, iotest.OneByteReader(strings.NewReader(body)), &doc, false)
+			require.NoError(t, err)
+			assert.Equal(t, 1, doc.ID)
+			assert.Equal(t, &Meta{Total: 2}, rawBody.Meta)
+			assert.Nil(t, rawBody.Data)
+
+			rawBody, err = decodeBody("application/json", size, strings.NewReader(body), nil, false)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"id": 1, "title": "文档"}`, string(rawBody.Data))
+		})
+	}
+}
+
+func TestDecodeBody_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{"truncated", "application/json", `{"data": {"id": 1`, "invalid JSON response body"},
+		{"html", "text/html", `<html></html>`, "non-JSON response body (text/html): <html></html>"},
+		{"plain text", "", `Bad Gateway`, "non-JSON response body: Bad Gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeBody(tt.contentType, -1, strings.NewReader(tt.body), nil, true)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMaxBytesReader(t *testing.T) {
+	data, err := io.ReadAll(newMaxBytesReader(strings.NewReader("hello"), 5))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	data, err = io.ReadAll(newMaxBytesReader(strings.NewReader("hello world"), 5))
+	assert.ErrorIs(t, err, ErrResponseTooLarge)
+	assert.Equal(t, "hello", string(data))
+
+	data, err = io.ReadAll(newMaxBytesReader(strings.NewReader("hello world"), 0))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestClient_MaxResponseSize(t *testing.T) {
+	body := loadData(t, "internal/testdata/api/doc/get_doc.json")
+
+	for _, chunked := range []bool{false, true} {
+		t.Run(fmt.Sprintf("chunked %v", chunked), func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if chunked {
+					w.(http.Flusher).Flush()
+				}
+				_, _ = io.Copy(w, bytes.NewReader(body))
+			}))
+			require.NoError(t, WithMaxResponseSize(64)(client))
+
+			_, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+			assert.ErrorIs(t, err, ErrResponseTooLarge)
+			assert.ErrorContains(t, err, "exceeds 64 bytes")
+
+			require.NoError(t, WithMaxResponseSize(int64(len(body)))(client))
+			doc, _, err := client.DocService.GetDoc(ctx, "org/book", "doc")
+			require.NoError(t, err)
+			assert.Equal(t, 200952222, doc.ID)
+		})
+	}
+}
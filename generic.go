@@ -0,0 +1,52 @@
+package yuque
+
+import (
+	"context"
+	"net/http"
+)
+
+// Get 发送 GET 请求, 并将响应的 data 字段解码为 T
+//
+// 用于 SDK 尚未封装的接口, 与各 Service 共用请求构建、信封解码及错误处理
+//
+// path: 相对于 BaseURL 的路径, 如 "repos/1/docs"
+// query: 查询参数, 以 url tag 编码的结构体, 可为 nil; 也可通过 WithRequestQuery 传入
+func Get[T any](ctx context.Context, c *Client, path string, query any, opts ...RequestOption) (*T, *Response, error) {
+	return send[T](ctx, c, http.MethodGet, path, query, opts)
+}
+
+// Post 发送 POST 请求, body 以 JSON 编码, 并将响应的 data 字段解码为 T
+//
+// path: 相对于 BaseURL 的路径, 如 "repos/1/docs"
+func Post[T any](ctx context.Context, c *Client, path string, body any, opts ...RequestOption) (*T, *Response, error) {
+	return send[T](ctx, c, http.MethodPost, path, body, opts)
+}
+
+// Put 发送 PUT 请求, body 以 JSON 编码, 并将响应的 data 字段解码为 T
+//
+// path: 相对于 BaseURL 的路径, 如 "repos/1/docs/intro"
+func Put[T any](ctx context.Context, c *Client, path string, body any, opts ...RequestOption) (*T, *Response, error) {
+	return send[T](ctx, c, http.MethodPut, path, body, opts)
+}
+
+// Delete 发送 DELETE 请求, 并将响应的 data 字段解码为 T
+//
+// path: 相对于 BaseURL 的路径, 如 "repos/1/docs/intro"
+func Delete[T any](ctx context.Context, c *Client, path string, opts ...RequestOption) (*T, *Response, error) {
+	return send[T](ctx, c, http.MethodDelete, path, nil, opts)
+}
+
+func send[T any](ctx context.Context, c *Client, method, path string, data any, opts []RequestOption) (*T, *Response, error) {
+	req, err := c.NewRequest(ctx, method, path, data, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := new(T)
+	resp, err := c.Do(req, v)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, nil
+}
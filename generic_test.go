@@ -0,0 +1,84 @@
+package yuque
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/repos/1/docs", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("limit"))
+		assert.Equal(t, "hits", r.URL.Query().Get("optional_properties"))
+
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/get_docs.json"))
+	}))
+
+	docs, resp, err := Get[[]*Doc](ctx, client, "repos/1/docs", &GetDocsRequest{Limit: new(1)},
+		WithRequestQuery(url.Values{"optional_properties": {"hits"}}))
+	require.NoError(t, err)
+	assert.NotEmpty(t, *docs)
+	assert.Positive(t, resp.Total())
+
+	raw, _, err := Get[json.RawMessage](ctx, client, "repos/1/docs", nil, WithRequestQuery(url.Values{
+		"limit":               {"1"},
+		"optional_properties": {"hits"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, byte('['), (*raw)[0])
+}
+
+func TestPostPutDelete(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/1/docs/intro", r.URL.Path)
+
+		if r.Method != http.MethodDelete {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"title":"入门"}`, string(body))
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"id":1,"slug":"intro","title":"` + r.Method + `"}}`))
+	}))
+
+	for _, tt := range []struct {
+		method string
+		send   func() (*Doc, *Response, error)
+	}{
+		{http.MethodPost, func() (*Doc, *Response, error) {
+			return Post[Doc](ctx, client, "repos/1/docs/intro", &UpdateDocRequest{Title: new("入门")})
+		}},
+		{http.MethodPut, func() (*Doc, *Response, error) {
+			return Put[Doc](ctx, client, "repos/1/docs/intro", map[string]string{"title": "入门"})
+		}},
+		{http.MethodDelete, func() (*Doc, *Response, error) {
+			return Delete[Doc](ctx, client, "repos/1/docs/intro")
+		}},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			doc, resp, err := tt.send()
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, &Doc{ID: 1, Slug: "intro", Title: tt.method}, doc)
+		})
+	}
+}
+
+func TestGet_Error(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status":404,"message":"Not Found"}`))
+	}))
+
+	doc, resp, err := Get[Doc](ctx, client, "repos/1/docs/missing", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, doc)
+	assert.Nil(t, resp)
+}
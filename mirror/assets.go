@@ -0,0 +1,381 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// AssetsDir is the folder of the mirror directory holding the assets
+// downloaded by an export WithAssets.
+const AssetsDir = "assets"
+
+const (
+	// defaultAssetConcurrency is the number of concurrent downloads of WithAssets(0).
+	defaultAssetConcurrency = 4
+
+	// defaultAssetTimeout is the timeout of each download of the default asset client.
+	defaultAssetTimeout = time.Minute
+
+	// defaultAssetMaxSize is the maximum size of an asset in bytes, see WithAssetMaxSize.
+	defaultAssetMaxSize = 100 << 20
+)
+
+// defaultAssetClient downloads the assets unless WithAssetClient is given.
+var defaultAssetClient = &http.Client{Timeout: defaultAssetTimeout}
+
+// WithAssets makes Export download the images and attachments referenced by
+// the docs into AssetsDir, at most concurrency at a time, and rewrite their
+// references to relative paths, so that the export does not depend on
+// Yuque's CDN. Push restores the references to the original URLs.
+//
+// Images are those of Markdown images and HTML img tags, and attachments the
+// links to Yuque attachments. Each URL is downloaded once, and not again by
+// later exports; assets failing to download are left remote.
+func WithAssets(concurrency int) Option {
+	return func(m *Mirror) {
+		if concurrency <= 0 {
+			concurrency = defaultAssetConcurrency
+		}
+		m.assets = concurrency
+	}
+}
+
+// WithAssetClient sets the HTTP client downloading the assets, by default a
+// client timing out after a minute.
+func WithAssetClient(client *http.Client) Option {
+	return func(m *Mirror) {
+		m.assetClient = client
+	}
+}
+
+// WithAssetMaxSize sets the maximum size of an asset in bytes, 0 disables the limit.
+//
+// The default is 100 MiB, larger assets fail to download and are left remote.
+func WithAssetMaxSize(size int64) Option {
+	return func(m *Mirror) {
+		m.assetMaxSize = size
+	}
+}
+
+var (
+	// Markdown images and links, ![alt](url "title") and [text](url), unless escaped
+	markdownRef = regexp.MustCompile(`(?:^|[^\\])(!?)\[(?:[^\[\]\\\n]|\\.)*\]\(\s*<?([^\s()<>]+)`)
+
+	// HTML images and links, <img src="url"> and <a href="url">
+	htmlRef = regexp.MustCompile(`(?i)<(?:(img)\b[^>]*?\ssrc|a\b[^>]*?\shref)\s*=\s*["']([^"']*)["']`)
+)
+
+// rewriteRefs replaces the Markdown and HTML image and link references of the
+// body by the result of fn, called with each reference and whether it is an image.
+func rewriteRefs(body string, fn func(ref string, image bool) string) string {
+	body = replaceGroup(body, markdownRef, func(groups []string) string {
+		return fn(groups[2], groups[1] == "!")
+	})
+	return replaceGroup(body, htmlRef, func(groups []string) string {
+		ref := html.UnescapeString(groups[2])
+		if rewritten := fn(ref, groups[1] != ""); rewritten != ref {
+			return html.EscapeString(rewritten)
+		}
+		return groups[2]
+	})
+}
+
+// replaceGroup replaces the last group of each match of re by the result of
+// fn, called with the groups of the match.
+func replaceGroup(s string, re *regexp.Regexp, fn func(groups []string) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = s[loc[2*i]:loc[2*i+1]]
+			}
+		}
+
+		start, end := loc[len(loc)-2], loc[len(loc)-1]
+		sb.WriteString(s[last:start])
+		sb.WriteString(fn(groups))
+		last = end
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// assetURL returns the URL of the asset referenced by ref, without its
+// fragment, and the fragment; ok is false if ref is not an asset.
+func assetURL(ref string, image bool) (u, fragment string, ok bool) {
+	u, fragment, _ = strings.Cut(ref, "#")
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", "", false
+	}
+	if !image && !strings.Contains(parsed.Path, "/attachments/") {
+		return "", "", false
+	}
+	if fragment != "" {
+		fragment = "#" + fragment
+	}
+	return u, fragment, true
+}
+
+// relativePath returns the path of the target relative to the folder of the file,
+// both slash separated paths relative to the mirror directory.
+func relativePath(file, target string) string {
+	return strings.Repeat("../", strings.Count(file, "/")) + target
+}
+
+// unlocalize returns the body of the file with the references to the assets
+// of the manifest restored to their URLs, and these URLs.
+func (m *Manifest) unlocalize(file, body string) (string, []string) {
+	if m == nil || len(m.Assets) == 0 {
+		return body, nil
+	}
+
+	urls := make(map[string]string, len(m.Assets))
+	for u, p := range m.Assets {
+		urls[p] = u
+	}
+
+	var restored []string
+	body = rewriteRefs(body, func(ref string, _ bool) string {
+		rel, fragment, _ := strings.Cut(ref, "#")
+		if strings.Contains(rel, ":") {
+			return ref
+		}
+		u, ok := urls[path.Join(path.Dir(file), rel)]
+		if !ok {
+			return ref
+		}
+		restored = append(restored, u)
+		if fragment != "" {
+			return u + "#" + fragment
+		}
+		return u
+	})
+	return body, restored
+}
+
+// asset is an asset of an export.
+type asset struct {
+	path       string // slash separated path relative to the mirror directory, empty if not downloaded
+	downloaded bool   // downloaded by this export
+	err        error
+}
+
+// localizer downloads the assets of an export, each URL once.
+type localizer struct {
+	dir      string
+	client   *http.Client
+	maxSize  int64
+	sem      chan struct{}
+	previous map[string]string
+
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	assets map[string]*asset
+}
+
+func (m *Mirror) newLocalizer(previous *Manifest) *localizer {
+	l := &localizer{
+		dir:     m.dir,
+		client:  m.assetClient,
+		maxSize: m.assetMaxSize,
+		sem:     make(chan struct{}, m.assets),
+		assets:  make(map[string]*asset),
+	}
+	if l.client == nil {
+		l.client = defaultAssetClient
+	}
+	if previous != nil {
+		l.previous = previous.Assets
+	}
+	return l
+}
+
+// fetch starts downloading the assets of the doc not seen yet.
+func (l *localizer) fetch(ctx context.Context, doc *yuque.Doc) {
+	for _, body := range []*string{doc.Body, doc.BodyHTML} {
+		if body == nil {
+			continue
+		}
+		rewriteRefs(*body, func(ref string, image bool) string {
+			if u, _, ok := assetURL(ref, image); ok {
+				l.start(ctx, u)
+			}
+			return ref
+		})
+	}
+}
+
+func (l *localizer) start(ctx context.Context, u string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.assets[u]; ok {
+		return
+	}
+	a := &asset{}
+	l.assets[u] = a
+
+	// downloaded by a previous export
+	if p, ok := l.previous[u]; ok {
+		if _, err := os.Stat(filepath.Join(l.dir, filepath.FromSlash(p))); err == nil {
+			a.path = p
+			return
+		}
+	}
+
+	l.wg.Go(func() {
+		select {
+		case l.sem <- struct{}{}:
+			defer func() { <-l.sem }()
+		case <-ctx.Done():
+			a.err = ctx.Err()
+			return
+		}
+
+		a.path, a.err = l.download(ctx, u)
+		a.downloaded = a.err == nil
+	})
+}
+
+// download writes the asset to AssetsDir, named after the hash of its URL.
+func (l *localizer) download(ctx context.Context, u string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := l.client.Do(req) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("mirror: download %s: %s", u, resp.Status)
+	}
+	if l.maxSize > 0 && resp.ContentLength > l.maxSize {
+		return "", fmt.Errorf("mirror: download %s: %w", u, l.tooLarge())
+	}
+
+	name := path.Join(AssetsDir, hashContent([]byte(u))[:16]+assetExt(u, resp.Header.Get("Content-Type")))
+	dir := filepath.Join(l.dir, AssetsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	// written aside, so that a failed download leaves no partial asset
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	// read one byte past the limit to tell an exact fit from an overflow
+	var body io.Reader = resp.Body
+	if l.maxSize > 0 {
+		body = io.LimitReader(resp.Body, l.maxSize+1)
+	}
+	n, err := io.Copy(tmp, body)
+	if err == nil && l.maxSize > 0 && n > l.maxSize {
+		err = l.tooLarge()
+	}
+	if err != nil {
+		_ = tmp.Close()
+		return "", fmt.Errorf("mirror: download %s: %w", u, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(l.dir, filepath.FromSlash(name))); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (l *localizer) tooLarge() error {
+	return fmt.Errorf("asset exceeds %d bytes", l.maxSize)
+}
+
+var assetExtPattern = regexp.MustCompile(`^\.[a-z0-9]{1,5}$`)
+
+// assetExt returns the extension of the asset, from its URL or else its content type.
+func assetExt(u, contentType string) string {
+	if parsed, err := url.Parse(u); err == nil {
+		if ext := strings.ToLower(path.Ext(parsed.Path)); assetExtPattern.MatchString(ext) {
+			return ext
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "image/jpeg" {
+			// the first of .jfif, .jpe, .jpeg, .jpg...
+			return ".jpg"
+		}
+		if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			return exts[0]
+		}
+	}
+	return ""
+}
+
+// wait waits for the downloads, returning the error of the context if canceled.
+func (l *localizer) wait(ctx context.Context) error {
+	l.wg.Wait()
+	return ctx.Err()
+}
+
+// localize returns a copy of the doc of the file, with the references to the
+// downloaded assets rewritten to relative paths.
+func (l *localizer) localize(doc *yuque.Doc, file string) *yuque.Doc {
+	rewrite := func(ref string, image bool) string {
+		u, fragment, ok := assetURL(ref, image)
+		if !ok {
+			return ref
+		}
+		if a := l.assets[u]; a != nil && a.path != "" {
+			return relativePath(file, a.path) + fragment
+		}
+		return ref
+	}
+
+	localized := *doc
+	if doc.Body != nil {
+		localized.Body = new(rewriteRefs(*doc.Body, rewrite))
+	}
+	if doc.BodyHTML != nil {
+		localized.BodyHTML = new(rewriteRefs(*doc.BodyHTML, rewrite))
+	}
+	return &localized
+}
+
+// record adds the assets to the manifest and the result.
+func (l *localizer) record(manifest *Manifest, result *ExportResult) {
+	for _, u := range slices.Sorted(maps.Keys(l.assets)) {
+		a := l.assets[u]
+		switch {
+		case a.err != nil:
+			result.FailedAssets = append(result.FailedAssets, u)
+		case a.downloaded:
+			result.Downloaded = append(result.Downloaded, a.path)
+		}
+		if a.path != "" {
+			manifest.Assets[u] = a.path
+		}
+	}
+}
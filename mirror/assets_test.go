@@ -0,0 +1,210 @@
+package mirror
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteRefs(t *testing.T) {
+	body := `# Doc
+
+![a](https://cdn.example.com/a.png "A") and [![b](https://cdn.example.com/b.png)](https://example.com/page)
+[report.pdf](https://www.yuque.com/attachments/yuque/0/report.pdf) \[not](a link)
+<p><IMG alt="c" data-src="x" src="https://cdn.example.com/c.png?w=1&amp;h=2"></p>
+<a href='https://example.com/d'>d</a>
+`
+
+	type ref struct {
+		ref   string
+		image bool
+	}
+	var refs []ref
+	rewritten := rewriteRefs(body, func(r string, image bool) string {
+		refs = append(refs, ref{r, image})
+		return r
+	})
+	assert.Equal(t, body, rewritten)
+	assert.Equal(t, []ref{
+		{"https://cdn.example.com/a.png", true},
+		{"https://cdn.example.com/b.png", true},
+		{"https://www.yuque.com/attachments/yuque/0/report.pdf", false},
+		{"https://cdn.example.com/c.png?w=1&h=2", true},
+		{"https://example.com/d", false},
+	}, refs)
+
+	rewritten = rewriteRefs(body, func(r string, image bool) string {
+		if _, _, ok := assetURL(r, image); ok {
+			return "local?" + r[len("https://"):]
+		}
+		return r
+	})
+	assert.Contains(t, rewritten, `![a](local?cdn.example.com/a.png "A")`)
+	assert.Contains(t, rewritten, `[report.pdf](local?www.yuque.com/attachments/yuque/0/report.pdf)`)
+	assert.Contains(t, rewritten, `src="local?cdn.example.com/c.png?w=1&amp;h=2"`)
+	assert.Contains(t, rewritten, `<a href='https://example.com/d'>`)
+}
+
+func TestAssetURL(t *testing.T) {
+	for _, tt := range []struct {
+		ref      string
+		image    bool
+		url      string
+		fragment string
+	}{
+		{ref: "https://cdn.nlark.com/yuque/0/a.png#averageHue=%23f9f8f8&id=u1", image: true, url: "https://cdn.nlark.com/yuque/0/a.png", fragment: "#averageHue=%23f9f8f8&id=u1"},
+		{ref: "http://example.com/a", image: true, url: "http://example.com/a"},
+		{ref: "https://www.yuque.com/attachments/yuque/0/a.zip", url: "https://www.yuque.com/attachments/yuque/0/a.zip"},
+		{ref: "https://www.yuque.com/yuque/guide"},
+		{ref: "assets/a.png", image: true},
+		{ref: "data:image/png;base64,AAAA", image: true},
+	} {
+		u, fragment, ok := assetURL(tt.ref, tt.image)
+		assert.Equal(t, tt.url != "", ok, tt.ref)
+		assert.Equal(t, tt.url, u, tt.ref)
+		assert.Equal(t, tt.fragment, fragment, tt.ref)
+	}
+}
+
+// assetServer serves assets, counting the requests of each path.
+type assetServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	hits    map[string]int
+	running int
+	max     int
+}
+
+func newAssetServer(t *testing.T) *assetServer {
+	s := &assetServer{hits: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		s.running++
+		s.max = max(s.max, s.running)
+		s.mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		s.mu.Lock()
+		s.running--
+		s.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/a.png", "/yuque/attachments/x.pdf":
+			_, _ = w.Write([]byte("asset " + r.URL.Path))
+		case "/b":
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("jpeg"))
+		case "/chunked.png":
+			// without Content-Length
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte("chunked asset"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *assetServer) assetPath(p, ext string) string {
+	return path.Join(AssetsDir, hashContent([]byte(s.URL + p))[:16]+ext)
+}
+
+func TestMirror_Export_Assets(t *testing.T) {
+	assets := newAssetServer(t)
+	repo := newTestRepo(t)
+	intro := "# Intro\n\n" +
+		"![a](" + assets.URL + "/a.png#averageHue=%23fff)\n" +
+		"![b](" + assets.URL + "/b)\n" +
+		"[x.pdf](" + assets.URL + "/yuque/attachments/x.pdf)\n" +
+		"[page](" + assets.URL + "/page)\n" +
+		"![missing](" + assets.URL + "/missing.png)\n"
+	advanced := repo.addDoc("", "advanced", "Advanced", "# Advanced")
+	repo.addDoc(advanced, "cache", "Cache", `<img src="`+assets.URL+`/a.png">`)
+	repo.addDoc("", "intro", "Intro", intro)
+
+	dir := t.TempDir()
+	mirror := New(repo.client, repo.book.ID, dir, WithAssets(2), WithAssetClient(assets.Client()))
+	result, err := mirror.Export(ctx)
+	require.NoError(t, err)
+
+	a, b, x := assets.assetPath("/a.png", ".png"), assets.assetPath("/b", ".jpg"), assets.assetPath("/yuque/attachments/x.pdf", ".pdf")
+	assert.ElementsMatch(t, []string{a, b, x}, result.Downloaded)
+	assert.Equal(t, []string{assets.URL + "/missing.png"}, result.FailedAssets)
+	assert.Equal(t, 1, assets.hits["/a.png"])
+	assert.LessOrEqual(t, assets.max, 2)
+
+	content := readFile(t, dir, "Intro.md")
+	assert.Contains(t, content, "![a]("+a+"#averageHue=%23fff)\n![b]("+b+")\n[x.pdf]("+x+")\n")
+	assert.Contains(t, content, "[page]("+assets.URL+"/page)\n![missing]("+assets.URL+"/missing.png)\n")
+	assert.Contains(t, readFile(t, dir, "Advanced/Cache.md"), `<img src="../`+a+`">`)
+	assert.Equal(t, "asset /a.png", readFile(t, dir, a))
+
+	// downloaded once
+	result, err = mirror.Export(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Downloaded)
+	assert.Empty(t, result.Written)
+	assert.Equal(t, 1, assets.hits["/a.png"])
+
+	statuses, err := mirror.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]DocState{"advanced": DocUnchanged, "cache": DocUnchanged, "intro": DocUnchanged}, states(statuses))
+
+	// pushed with the original URLs
+	editFile(t, dir, "Intro.md", "# Intro", "# Intro v2")
+	pushed, err := mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Intro.md"}, pushed.Updated)
+	doc, _, err := repo.client.DocService.GetDoc(ctx, repo.book.ID, "intro")
+	require.NoError(t, err)
+	assert.Equal(t, "# Intro v2"+intro[len("# Intro"):], *doc.Body)
+
+	// assets no longer referenced are removed
+	repo.updateBody("intro", "# Intro v3\n")
+	result, err = mirror.Export(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{b, x}, result.Removed)
+	assert.NoFileExists(t, filepath.Join(dir, filepath.FromSlash(b)))
+	assert.FileExists(t, filepath.Join(dir, filepath.FromSlash(a)))
+
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{assets.URL + "/a.png": a}, manifest.Assets)
+
+	// without assets, references are left remote
+	result, err = New(repo.client, repo.book.ID, dir).Export(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Advanced/Cache.md"}, result.Written)
+	assert.Equal(t, []string{a}, result.Removed)
+	assert.NoDirExists(t, filepath.Join(dir, AssetsDir))
+}
+
+func TestMirror_Export_AssetMaxSize(t *testing.T) {
+	assets := newAssetServer(t)
+	repo := newTestRepo(t)
+	repo.addDoc("", "intro", "Intro", "![a]("+assets.URL+"/a.png)\n![c]("+assets.URL+"/chunked.png)\n![b]("+assets.URL+"/b)\n")
+
+	dir := t.TempDir()
+	result, err := New(repo.client, repo.book.ID, dir, WithAssets(0), WithAssetClient(assets.Client()), WithAssetMaxSize(4)).Export(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{assets.assetPath("/b", ".jpg")}, result.Downloaded)
+	assert.Equal(t, []string{assets.URL + "/a.png", assets.URL + "/chunked.png"}, result.FailedAssets)
+	assert.NoFileExists(t, filepath.Join(dir, filepath.FromSlash(assets.assetPath("/chunked.png", ".png"))))
+}
+
+func TestMirror_DefaultAssetClient(t *testing.T) {
+	l := New(nil, 1, t.TempDir(), WithAssets(0)).newLocalizer(nil)
+	assert.Equal(t, defaultAssetTimeout, l.client.Timeout)
+	assert.Equal(t, int64(defaultAssetMaxSize), l.maxSize)
+}
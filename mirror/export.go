@@ -0,0 +1,339 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// ExportResult reports the files changed by an export, as slash separated
+// paths relative to the mirror directory.
+type ExportResult struct {
+	Written   []string // files created or changed
+	Unchanged []string // files already up to date
+	Removed   []string // files of the previous export no longer in the TOC
+
+	// files changed in the directory since the last sync, left untouched
+	LocalChanged []string // only in the directory
+	Conflicted   []string // also in the repo, or whose node left the TOC
+
+	// assets downloaded, and URLs of the assets left remote as their
+	// download failed, see WithAssets
+	Downloaded   []string
+	FailedAssets []string
+}
+
+// exporter is the state of an export.
+type exporter struct {
+	m        *Mirror
+	names    names
+	manifest *Manifest
+	files    map[string][]byte
+
+	// assets localizes the docs, nil unless WithAssets
+	assets *localizer
+	docs   map[string]*yuque.Doc
+}
+
+// Export writes the repo to the mirror directory, fetching each doc of the TOC.
+//
+// Files are only rewritten if their content changed, and files of the previous
+// export whose nodes left the TOC are removed; other files are left untouched.
+// Files changed in the directory since the last sync are kept, unless the
+// mirror is created WithForce, so that Push can publish them.
+//
+// Assets of the previous export no longer referenced are removed.
+func (m *Mirror) Export(ctx context.Context) (*ExportResult, error) {
+	previous, err := ReadManifest(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	book, _, err := m.client.RepoService.GetRepo(ctx, m.bookID)
+	if err != nil {
+		return nil, err
+	}
+	tocs, _, err := m.client.DocService.GetTOCs(ctx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &exporter{
+		m:        m,
+		names:    names{"": {strings.ToLower(ManifestFile): true}},
+		manifest: &Manifest{BookID: book.ID, Namespace: book.Namespace, Entries: []*Entry{}},
+		files:    make(map[string][]byte),
+		docs:     make(map[string]*yuque.Doc),
+	}
+	if m.assets > 0 {
+		e.assets = m.newLocalizer(previous)
+		e.names.reserve("", AssetsDir)
+	}
+	if err := e.walk(ctx, book, yuque.NewTOCTree(tocs).Roots(), ""); err != nil {
+		return nil, err
+	}
+
+	result := &ExportResult{}
+	if e.assets != nil {
+		if err := e.localize(ctx, result); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range e.manifest.Entries {
+		if !entry.IsFile() {
+			continue
+		}
+
+		name := filepath.Join(m.dir, filepath.FromSlash(entry.Path))
+		current, err := os.ReadFile(name)
+		switch {
+		case err == nil && bytes.Equal(current, e.files[entry.Path]):
+			result.Unchanged = append(result.Unchanged, entry.Path)
+			continue
+		case err == nil && !m.force:
+			base := previous.entry(entry.Path)
+			if !base.tracks(entry) || base.Hash == hashContent(current) {
+				break
+			}
+
+			if base.changedBy(entry) {
+				result.Conflicted = append(result.Conflicted, entry.Path)
+			} else {
+				result.LocalChanged = append(result.LocalChanged, entry.Path)
+			}
+			entry.keepState(base)
+			e.keepAssets(previous, entry.Path, current)
+			continue
+		}
+		if err := writeFile(name, e.files[entry.Path]); err != nil {
+			return nil, err
+		}
+		result.Written = append(result.Written, entry.Path)
+	}
+
+	if previous != nil {
+		if err := e.removeStale(previous, result); err != nil {
+			return nil, err
+		}
+		if err := e.removeStaleAssets(previous, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := e.manifest.write(m.dir); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (e *exporter) walk(ctx context.Context, book *yuque.Book, nodes []*yuque.TOCNode, dir string) error {
+	for _, node := range nodes {
+		entry := &Entry{Type: node.Type, UUID: node.UUID}
+
+		var content []byte
+		switch node.Type {
+		case yuque.TOCTypeTitle:
+			entry.Path = joinPath(dir, e.names.unique(dir, sanitizeName(node.Title, "untitled"), ""))
+			e.manifest.add(entry)
+			if err := e.walk(ctx, book, node.Children, entry.Path); err != nil {
+				return err
+			}
+			continue
+
+		case yuque.TOCTypeDoc:
+			doc, _, err := e.m.client.DocService.GetDoc(ctx, book.ID, node.DocID)
+			if err != nil {
+				return fmt.Errorf("mirror: get doc %q: %w", node.Title, err)
+			}
+			entry.DocID, entry.Slug = doc.ID, doc.Slug
+			entry.LatestVersionID, entry.ContentUpdatedAt = doc.LatestVersionID, doc.ContentUpdatedAt
+			content = docContent(doc)
+			entry.Path = e.filePath(dir, sanitizeName(node.Title, doc.Slug), len(node.Children) > 0)
+			if e.assets != nil {
+				e.assets.fetch(ctx, doc)
+				e.docs[entry.Path] = doc
+			}
+
+		case yuque.TOCTypeLink:
+			content = linkContent(node.TOC)
+			entry.Path = e.filePath(dir, sanitizeName(node.Title, "link"), len(node.Children) > 0)
+
+		default:
+			continue
+		}
+
+		entry.Hash = hashContent(content)
+		e.files[entry.Path] = content
+		e.manifest.add(entry)
+
+		if len(node.Children) > 0 {
+			if err := e.walk(ctx, book, node.Children, path.Dir(entry.Path)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// filePath allocates the path of a node file: name.md, or name/index.md for a node with children.
+func (e *exporter) filePath(dir, name string, hasChildren bool) string {
+	if !hasChildren {
+		return joinPath(dir, e.names.unique(dir, name, ".md"))
+	}
+
+	folder := joinPath(dir, e.names.unique(dir, name, ""))
+	e.names.reserve(folder, indexFile)
+	return joinPath(folder, indexFile)
+}
+
+// localize waits for the assets and rewrites the files of the docs to reference them.
+func (e *exporter) localize(ctx context.Context, result *ExportResult) error {
+	if err := e.assets.wait(ctx); err != nil {
+		return err
+	}
+
+	e.manifest.Assets = make(map[string]string)
+	e.assets.record(e.manifest, result)
+	for _, entry := range e.manifest.Entries {
+		if doc, ok := e.docs[entry.Path]; ok {
+			content := docContent(e.assets.localize(doc, entry.Path))
+			e.files[entry.Path], entry.Hash = content, hashContent(content)
+		}
+	}
+	return nil
+}
+
+// keepAssets keeps the assets of the previous export referenced by the file left untouched.
+func (e *exporter) keepAssets(previous *Manifest, name string, content []byte) {
+	_, urls := previous.unlocalize(name, string(content))
+	for _, u := range urls {
+		if e.manifest.Assets == nil {
+			e.manifest.Assets = make(map[string]string)
+		}
+		e.manifest.Assets[u] = previous.Assets[u]
+	}
+}
+
+// removeStaleAssets removes the assets of the previous export no longer referenced.
+func (e *exporter) removeStaleAssets(previous *Manifest, result *ExportResult) error {
+	kept := make(map[string]bool, len(e.manifest.Assets))
+	for _, p := range e.manifest.Assets {
+		kept[p] = true
+	}
+
+	for _, p := range slices.Sorted(maps.Values(previous.Assets)) {
+		if kept[p] {
+			continue
+		}
+		err := os.Remove(filepath.Join(e.m.dir, filepath.FromSlash(p)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		result.Removed = append(result.Removed, p)
+	}
+
+	removeEmptyDir(filepath.Join(e.m.dir, AssetsDir))
+	return nil
+}
+
+// removeStale removes the files and then empty folders of the previous export
+// that were not exported again, keeping the files changed since.
+func (e *exporter) removeStale(previous *Manifest, result *ExportResult) error {
+	var folders []string
+	for _, entry := range previous.Entries {
+		if e.manifest.entry(entry.Path) != nil {
+			continue
+		}
+		if !entry.IsFile() {
+			folders = append(folders, entry.Path)
+			continue
+		}
+
+		name := filepath.Join(e.m.dir, filepath.FromSlash(entry.Path))
+		if current, err := os.ReadFile(name); err == nil && !e.m.force && hashContent(current) != entry.Hash {
+			result.Conflicted = append(result.Conflicted, entry.Path)
+			continue
+		}
+		err := os.Remove(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		result.Removed = append(result.Removed, entry.Path)
+
+		if dir := path.Dir(entry.Path); path.Base(entry.Path) == indexFile && e.manifest.entry(dir) == nil {
+			folders = append(folders, dir)
+		}
+	}
+
+	// deepest first, so that nested empty folders are removed
+	slices.SortFunc(folders, func(a, b string) int { return strings.Count(b, "/") - strings.Count(a, "/") })
+	for _, folder := range folders {
+		removeEmptyDir(filepath.Join(e.m.dir, filepath.FromSlash(folder)))
+	}
+
+	return nil
+}
+
+// removeEmptyDir removes the directory if empty.
+func removeEmptyDir(dir string) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+		_ = os.Remove(dir)
+	}
+}
+
+// docContent returns the Markdown file of the doc.
+func docContent(doc *yuque.Doc) []byte {
+	fm := &FrontMatter{
+		Type:      yuque.TOCTypeDoc,
+		ID:        doc.ID,
+		Slug:      doc.Slug,
+		Title:     doc.Title,
+		CreatedAt: doc.CreatedAt,
+		UpdatedAt: doc.ContentUpdatedAt,
+	}
+	if doc.Format != nil && *doc.Format != yuque.DocFormatMarkdown {
+		fm.Format = *doc.Format
+	}
+	switch {
+	case doc.Creator != nil:
+		fm.Author = doc.Creator.Name
+	case doc.User != nil:
+		fm.Author = doc.User.Name
+	}
+	for _, tag := range doc.Tags {
+		fm.Tags = append(fm.Tags, tag.Title)
+	}
+
+	var body string
+	if doc.Body != nil {
+		body = *doc.Body
+	}
+	return markdownFile(fm, body)
+}
+
+// linkContent returns the Markdown stub of the link.
+func linkContent(toc *yuque.TOC) []byte {
+	fm := &FrontMatter{Type: yuque.TOCTypeLink, Title: toc.Title, URL: toc.URL}
+	return markdownFile(fm, fmt.Sprintf("[%s](%s)", toc.Title, toc.URL))
+}
+
+// markdownFile returns the front matter followed by the body, ending with a newline.
+func markdownFile(fm *FrontMatter, body string) []byte {
+	data := fm.Marshal()
+	data = append(data, body...)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	return data
+}
@@ -0,0 +1,193 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+	"github.com/flc1125/go-yuque/yuquetest"
+)
+
+var ctx = context.Background()
+
+// testRepo is a repo of the fake server.
+type testRepo struct {
+	t      *testing.T
+	client *yuque.Client
+	book   *yuque.Book
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	srv := yuquetest.NewServer(yuquetest.WithNow(func() time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := srv.NewClient(yuquetest.Token)
+	require.NoError(t, err)
+
+	book, _, err := client.RepoService.CreateUserRepo(ctx, yuquetest.Login, &yuque.CreateRepoRequest{
+		Name: new("Guide"),
+		Slug: new("guide"),
+	})
+	require.NoError(t, err)
+
+	return &testRepo{t: t, client: client, book: book}
+}
+
+// appendNode appends a node under the parent, the root if empty, and returns its UUID.
+func (r *testRepo) appendNode(parent string, req *yuque.UpdateTOCRequest) string {
+	req.Action = new(yuque.TOCActionAppendNode)
+	req.ActionMode = new(yuque.TOCActionModeChild)
+	if parent != "" {
+		req.TargetUUID = new(parent)
+	}
+
+	tocs, _, err := r.client.DocService.UpdateTOC(ctx, r.book.ID, req)
+	require.NoError(r.t, err)
+
+	tree := yuque.NewTOCTree(tocs)
+	siblings := tree.Roots()
+	if node, ok := tree.Node(parent); ok {
+		siblings = node.Children
+	}
+	return siblings[len(siblings)-1].UUID
+}
+
+func (r *testRepo) addDoc(parent, slug, title, body string) string {
+	doc, _, err := r.client.DocService.CreateDoc(ctx, r.book.ID, &yuque.CreateDocRequest{
+		Slug:  new(slug),
+		Title: new(title),
+		Body:  new(body),
+	})
+	require.NoError(r.t, err)
+
+	return r.appendNode(parent, &yuque.UpdateTOCRequest{Type: new(yuque.TOCTypeDoc), DocIDs: []int{doc.ID}})
+}
+
+func (r *testRepo) addTitle(parent, title string) string {
+	return r.appendNode(parent, &yuque.UpdateTOCRequest{Type: new(yuque.TOCTypeTitle), Title: new(title)})
+}
+
+func (r *testRepo) addLink(parent, title, url string) string {
+	return r.appendNode(parent, &yuque.UpdateTOCRequest{Type: new(yuque.TOCTypeLink), Title: new(title), URL: new(url)})
+}
+
+func (r *testRepo) removeNode(uuid string) {
+	_, _, err := r.client.DocService.UpdateTOC(ctx, r.book.ID, &yuque.UpdateTOCRequest{
+		Action:     new(yuque.TOCActionRemoveNode),
+		ActionMode: new(yuque.TOCActionModeChild),
+		NodeUUID:   new(uuid),
+	})
+	require.NoError(r.t, err)
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMirror_Export(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.addDoc("", "intro", "入门", "# 入门")
+	advanced := repo.addDoc("", "advanced", "进阶", "# 进阶")
+	repo.addDoc(advanced, "cache", "缓存", "# 缓存")
+	faq := repo.addTitle("", "FAQ")
+	repo.addDoc(faq, "faq-intro", "入门", "# FAQ")
+	repo.addDoc(faq, "faq-intro-2", "入门", "# FAQ 2")
+	repo.addLink(faq, "Yuque", "https://www.yuque.com")
+	repo.addDoc(faq, "slash", "a/b: c?", "")
+
+	dir := t.TempDir()
+	result, err := New(repo.client, "yuque/guide", dir).Export(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"入门.md",
+		"进阶/index.md",
+		"进阶/缓存.md",
+		"FAQ/入门.md",
+		"FAQ/入门-2.md",
+		"FAQ/Yuque.md",
+		"FAQ/a_b_ c_.md",
+	}, result.Written)
+	assert.Empty(t, result.Removed)
+
+	assert.Equal(t, `---
+type: "DOC"
+id: 3
+slug: "intro"
+title: "入门"
+created_at: 2024-01-01T00:00:00Z
+updated_at: 2024-01-01T00:00:00Z
+author: "Yuque"
+---
+# 入门
+`, readFile(t, dir, "入门.md"))
+
+	assert.Equal(t, `---
+type: "LINK"
+title: "Yuque"
+url: "https://www.yuque.com"
+---
+[Yuque](https://www.yuque.com)
+`, readFile(t, dir, "FAQ/Yuque.md"))
+
+	fm, body, err := ParseMarkdown([]byte(readFile(t, dir, "进阶/缓存.md")))
+	require.NoError(t, err)
+	assert.Equal(t, "cache", fm.Slug)
+	assert.Equal(t, "# 缓存\n", string(body))
+
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, repo.book.ID, manifest.BookID)
+	assert.Equal(t, "yuque/guide", manifest.Namespace)
+	require.Len(t, manifest.Entries, 8)
+	assert.Equal(t, &Entry{Path: "FAQ", Type: yuque.TOCTypeTitle, UUID: faq}, manifest.Entries[3])
+	assert.Equal(t, hashContent([]byte(readFile(t, dir, "入门.md"))), manifest.Entries[0].Hash)
+}
+
+func TestMirror_Export_Again(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.addDoc("", "intro", "入门", "# 入门")
+	advanced := repo.addDoc("", "advanced", "进阶", "# 进阶")
+	cache := repo.addDoc(advanced, "cache", "缓存", "# 缓存")
+	faq := repo.addTitle("", "FAQ")
+	repo.addLink(faq, "Yuque", "https://www.yuque.com")
+
+	dir := t.TempDir()
+	mirror := New(repo.client, repo.book.ID, dir)
+	_, err := mirror.Export(ctx)
+	require.NoError(t, err)
+
+	// files not exported are left untouched
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0o600))
+
+	result, err := mirror.Export(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Written)
+	assert.Len(t, result.Unchanged, 4)
+	assert.Empty(t, result.Removed)
+
+	// the doc without children becomes a file, the empty title folder is removed
+	repo.removeNode(cache)
+	repo.removeNode(faq)
+	_, _, err = repo.client.DocService.UpdateDoc(ctx, repo.book.ID, "intro", &yuque.UpdateDocRequest{Body: new("# 入门 v2")})
+	require.NoError(t, err)
+
+	result, err = mirror.Export(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"入门.md", "进阶.md"}, result.Written)
+	assert.Equal(t, []string{"进阶/index.md", "进阶/缓存.md", "FAQ/Yuque.md"}, result.Removed)
+
+	assert.NoDirExists(t, filepath.Join(dir, "进阶"))
+	assert.NoDirExists(t, filepath.Join(dir, "FAQ"))
+	assert.FileExists(t, filepath.Join(dir, "README.md"))
+	assert.Contains(t, readFile(t, dir, "入门.md"), "# 入门 v2")
+}
@@ -0,0 +1,175 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/flc1125/go-yuque"
+)
+
+const frontMatterDelim = "---"
+
+// FrontMatter is the YAML front matter of an exported Markdown file.
+type FrontMatter struct {
+	Type      yuque.TOCType   // DOC or LINK
+	ID        int             // doc ID
+	Slug      string          // doc slug
+	Title     string          // title of the doc or link
+	Format    yuque.DocFormat // body format, empty for Markdown
+	URL       string          // URL of the link
+	CreatedAt time.Time       // doc creation time
+	UpdatedAt time.Time       // doc content update time
+	Author    string          // name of the doc creator
+	Tags      []string        // doc tags
+	Extra     map[string]any  // unknown keys with their decoded YAML values
+}
+
+// Marshal returns the front matter, delimited by "---" lines.
+func (f *FrontMatter) Marshal() []byte {
+	var b bytes.Buffer
+	b.WriteString(frontMatterDelim + "\n")
+
+	writeString := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", key, quote(value))
+		}
+	}
+	writeTime := func(key string, t time.Time) {
+		if !t.IsZero() {
+			fmt.Fprintf(&b, "%s: %s\n", key, t.Format(time.RFC3339))
+		}
+	}
+
+	writeString("type", string(f.Type))
+	if f.ID != 0 {
+		fmt.Fprintf(&b, "id: %d\n", f.ID)
+	}
+	writeString("slug", f.Slug)
+	writeString("title", f.Title)
+	writeString("format", string(f.Format))
+	writeString("url", f.URL)
+	writeTime("created_at", f.CreatedAt)
+	writeTime("updated_at", f.UpdatedAt)
+	writeString("author", f.Author)
+	if len(f.Tags) > 0 {
+		tags := make([]string, len(f.Tags))
+		for i, tag := range f.Tags {
+			tags[i] = quote(tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(tags, ", "))
+	}
+	for _, key := range slices.Sorted(maps.Keys(f.Extra)) {
+		// values that cannot be encoded, such as funcs, are left out
+		if extra, err := yaml.Marshal(map[string]any{key: f.Extra[key]}); err == nil {
+			b.Write(extra)
+		}
+	}
+
+	b.WriteString(frontMatterDelim + "\n")
+	return b.Bytes()
+}
+
+// ParseMarkdown splits the Markdown file into its front matter and body.
+//
+// The front matter is parsed as YAML, so hand written front matter may use
+// block sequences, multi-line scalars and comments. A file without front
+// matter yields a nil front matter and the whole file as body.
+func ParseMarkdown(data []byte) (*FrontMatter, []byte, error) {
+	line, rest, _ := bytes.Cut(data, []byte("\n"))
+	if !isFrontMatterDelim(line) {
+		return nil, data, nil
+	}
+
+	for remaining := rest; len(remaining) > 0; {
+		line, next, _ := bytes.Cut(remaining, []byte("\n"))
+		if isFrontMatterDelim(line) {
+			f := &FrontMatter{}
+			if err := f.unmarshal(rest[:len(rest)-len(remaining)]); err != nil {
+				return nil, nil, err
+			}
+			return f, next, nil
+		}
+		remaining = next
+	}
+
+	return nil, nil, errors.New("mirror: unterminated front matter")
+}
+
+func isFrontMatterDelim(line []byte) bool {
+	return string(bytes.TrimRight(line, " \t\r")) == frontMatterDelim
+}
+
+func (f *FrontMatter) unmarshal(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("mirror: invalid front matter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return errors.New("mirror: front matter is not a mapping")
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i].Value
+		if err := f.set(key, mapping.Content[i+1]); err != nil {
+			return fmt.Errorf("mirror: invalid front matter %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (f *FrontMatter) set(key string, value *yaml.Node) error {
+	var err error
+	switch key {
+	case "type":
+		var s string
+		err = value.Decode(&s)
+		f.Type = yuque.TOCType(s)
+	case "id":
+		err = value.Decode(&f.ID)
+	case "slug":
+		err = value.Decode(&f.Slug)
+	case "title":
+		err = value.Decode(&f.Title)
+	case "format":
+		var s string
+		err = value.Decode(&s)
+		f.Format = yuque.DocFormat(s)
+	case "url":
+		err = value.Decode(&f.URL)
+	case "created_at":
+		err = value.Decode(&f.CreatedAt)
+	case "updated_at":
+		err = value.Decode(&f.UpdatedAt)
+	case "author":
+		err = value.Decode(&f.Author)
+	case "tags":
+		err = value.Decode(&f.Tags)
+	default:
+		var v any
+		if err = value.Decode(&v); err == nil {
+			if f.Extra == nil {
+				f.Extra = make(map[string]any)
+			}
+			f.Extra[key] = v
+		}
+	}
+	return err
+}
+
+// quote returns s as a double-quoted YAML string, which is also a JSON string.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+func TestFrontMatter(t *testing.T) {
+	fm := &FrontMatter{
+		Type:      yuque.TOCTypeDoc,
+		ID:        1,
+		Slug:      "intro",
+		Title:     `入门: "quoted"`,
+		Format:    yuque.DocFormatLake,
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 2, 8, 0, 0, 0, time.FixedZone("CST", 8*3600)),
+		Author:    "Yuque",
+		Tags:      []string{"a", "b, c"},
+		Extra:     map[string]any{"draft": true, "aliases": []any{"a"}},
+	}
+
+	data := fm.Marshal()
+	assert.Equal(t, `---
+type: "DOC"
+id: 1
+slug: "intro"
+title: "入门: \"quoted\""
+format: "lake"
+created_at: 2024-01-01T00:00:00Z
+updated_at: 2024-01-02T08:00:00+08:00
+author: "Yuque"
+tags: ["a", "b, c"]
+aliases:
+    - a
+draft: true
+---
+`, string(data))
+
+	got, body, err := ParseMarkdown(append(data, "# 入门\n"...))
+	require.NoError(t, err)
+	assert.Equal(t, "# 入门\n", string(body))
+	assert.True(t, fm.UpdatedAt.Equal(got.UpdatedAt))
+	got.UpdatedAt = fm.UpdatedAt
+	assert.Equal(t, fm, got)
+}
+
+func TestParseMarkdown(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		want *FrontMatter
+		body string
+	}{
+		{
+			name: "no front matter",
+			data: "# Title\n---\n",
+			body: "# Title\n---\n",
+		},
+		{
+			name: "hand written",
+			data: "---\r\n# comment\r\nslug: intro\r\ntitle: 'It''s'\r\ntags: [a, \"b\"]\r\n\r\n---\r\nbody",
+			want: &FrontMatter{Slug: "intro", Title: "It's", Tags: []string{"a", "b"}},
+			body: "body",
+		},
+		{
+			name: "block yaml",
+			data: "---\ntitle: >-\n  Long\n  title # not a comment\ntags:\n  - a # comment\n  - \"b\"\nnotes: |\n  line 1\n  line 2\n---\nbody",
+			want: &FrontMatter{
+				Title: "Long title # not a comment",
+				Tags:  []string{"a", "b"},
+				Extra: map[string]any{"notes": "line 1\nline 2\n"},
+			},
+			body: "body",
+		},
+		{
+			name: "empty",
+			data: "---\n---\n",
+			want: &FrontMatter{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := ParseMarkdown([]byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, fm)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestParseMarkdown_Invalid(t *testing.T) {
+	for _, data := range []string{
+		"---\nslug: intro\n",
+		"---\nno colon\n---\n",
+		"---\nid: one\n---\n",
+		"---\ntags: a, b\n---\n",
+		"---\ncreated_at: yesterday\n---\n",
+		"---\ntitle: [unclosed\n---\n",
+	} {
+		_, _, err := ParseMarkdown([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
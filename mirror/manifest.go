@@ -0,0 +1,155 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// ManifestFile is the name of the manifest in the mirror directory.
+const ManifestFile = ".yuque-mirror.json"
+
+// Manifest records the files of a mirror directory and the TOC nodes they
+// mirror, as of the last export.
+type Manifest struct {
+	BookID    int      `json:"book_id"`
+	Namespace string   `json:"namespace"`
+	Entries   []*Entry `json:"entries"`
+
+	// Assets maps the URLs of the downloaded assets to their slash separated
+	// paths relative to the mirror directory.
+	Assets map[string]string `json:"assets,omitempty"`
+
+	// paths indexes the entries by path, built on first lookup
+	paths map[string]*Entry
+}
+
+// Entry is a mirrored TOC node.
+type Entry struct {
+	// Path is the slash separated path relative to the mirror directory, a
+	// folder for TITLE nodes and a Markdown file otherwise.
+	Path string        `json:"path"`
+	Type yuque.TOCType `json:"type"`
+	UUID string        `json:"uuid,omitempty"`
+
+	DocID            int       `json:"doc_id,omitempty"`
+	Slug             string    `json:"slug,omitempty"`
+	LatestVersionID  int       `json:"latest_version_id,omitempty"`
+	ContentUpdatedAt time.Time `json:"content_updated_at,omitzero"`
+
+	// Hash is the SHA-256 of the file content as written, empty for folders.
+	Hash string `json:"hash,omitempty"`
+}
+
+// IsFile reports whether the entry is a file rather than a folder.
+func (e *Entry) IsFile() bool {
+	return e.Type != yuque.TOCTypeTitle
+}
+
+// remoteChanged reports whether the doc, as of its latest version and content
+// update time, changed since the entry was synced.
+func (e *Entry) remoteChanged(latestVersionID int, contentUpdatedAt time.Time) bool {
+	if e.LatestVersionID != 0 && latestVersionID != 0 {
+		return e.LatestVersionID != latestVersionID
+	}
+	return !e.ContentUpdatedAt.Equal(contentUpdatedAt)
+}
+
+// tracks reports whether the entry, nil if absent, mirrored the same node as other.
+func (e *Entry) tracks(other *Entry) bool {
+	switch {
+	case e == nil || !e.IsFile() || e.Type != other.Type:
+		return false
+	case e.Type == yuque.TOCTypeDoc:
+		return e.DocID == other.DocID
+	default:
+		return e.UUID == other.UUID
+	}
+}
+
+// changedBy reports whether the node of the entry changed in the repo, as
+// exported again in other.
+func (e *Entry) changedBy(other *Entry) bool {
+	if e.Type == yuque.TOCTypeDoc {
+		return e.remoteChanged(other.LatestVersionID, other.ContentUpdatedAt)
+	}
+	return e.Hash != other.Hash
+}
+
+// keepState keeps the sync state of the base entry, for a doc left unsynced.
+func (e *Entry) keepState(base *Entry) {
+	e.Hash, e.LatestVersionID, e.ContentUpdatedAt = base.Hash, base.LatestVersionID, base.ContentUpdatedAt
+}
+
+// ReadManifest reads the manifest of the mirror directory, nil if absent.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Manifest) write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, ManifestFile), append(data, '\n'))
+}
+
+// entry returns the first entry of the path, nil if absent.
+func (m *Manifest) entry(path string) *Entry {
+	if m == nil {
+		return nil
+	}
+	if m.paths == nil {
+		m.paths = make(map[string]*Entry, len(m.Entries))
+		for _, e := range m.Entries {
+			m.index(e)
+		}
+	}
+	return m.paths[path]
+}
+
+// add appends the entry, keeping the index of paths up to date.
+func (m *Manifest) add(e *Entry) {
+	m.Entries = append(m.Entries, e)
+	if m.paths != nil {
+		m.index(e)
+	}
+}
+
+func (m *Manifest) index(e *Entry) {
+	if _, ok := m.paths[e.Path]; !ok {
+		m.paths[e.Path] = e
+	}
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFile writes the file, creating its parent directories.
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644) //nolint:gosec
+}
@@ -0,0 +1,230 @@
+package mirror
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers of a three-way merge, as written by diff3.
+const (
+	markerLocal  = "<<<<<<< local\n"
+	markerBase   = "||||||| base\n"
+	markerSep    = "=======\n"
+	markerRemote = ">>>>>>> remote\n"
+)
+
+// merge3 merges the changes from base to local and to remote line by line,
+// in the manner of diff3. Overlapping changes are written between conflict
+// markers, in which case clean is false.
+func merge3(base, local, remote string) (merged string, clean bool) {
+	b, l, r := splitLines(base), splitLines(local), splitLines(remote)
+	bl, br := matchLines(b, l), matchLines(b, r)
+
+	var sb strings.Builder
+	clean = true
+	i, j, k := 0, 0, 0
+	for {
+		// the next base line kept on both sides ends the current chunk
+		next := i
+		for next < len(b) && (bl[next] < 0 || br[next] < 0) {
+			next++
+		}
+		nl, nr := len(l), len(r)
+		if next < len(b) {
+			nl, nr = bl[next], br[next]
+		}
+
+		cb, cl, cr := b[i:next], l[j:nl], r[k:nr]
+		switch {
+		case slices.Equal(cl, cb):
+			writeLines(&sb, cr)
+		case slices.Equal(cr, cb), slices.Equal(cl, cr):
+			writeLines(&sb, cl)
+		default:
+			clean = false
+			sb.WriteString(markerLocal)
+			writeLines(&sb, cl)
+			sb.WriteString(markerBase)
+			writeLines(&sb, cb)
+			sb.WriteString(markerSep)
+			writeLines(&sb, cr)
+			sb.WriteString(markerRemote)
+		}
+
+		if next == len(b) {
+			return sb.String(), clean
+		}
+		sb.WriteString(b[next])
+		i, j, k = next+1, nl+1, nr+1
+	}
+}
+
+// splitLines splits s after each newline, adding one to the last line if missing.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return strings.SplitAfter(s, "\n")[:strings.Count(s, "\n")]
+}
+
+func writeLines(sb *strings.Builder, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+}
+
+// matchLines returns, for each line of a, the index of the same line in b
+// along a shortest edit script, -1 if unmatched.
+//
+// It uses the linear space variant of Myers' diff, bisecting the edit graph
+// at the middle snake, so that large docs never need a len(a)*len(b) table.
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// lines are compared by ID
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		interned := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			interned[i] = id
+		}
+		return interned
+	}
+
+	d := &lineDiff{a: intern(a), b: intern(b), match: match}
+	d.compare(0, len(a), 0, len(b))
+	return match
+}
+
+// lineDiff matches the lines of a to those of b.
+type lineDiff struct {
+	a, b   []int
+	match  []int
+	v1, v2 []int // furthest reaching paths of bisect, reused across calls
+}
+
+// compare matches a[a0:a1] to b[b0:b1].
+func (d *lineDiff) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.match[a0] = b0
+		a0++
+		b0++
+	}
+	for a0 < a1 && b0 < b1 && d.a[a1-1] == d.b[b1-1] {
+		a1--
+		b1--
+		d.match[a1] = b1
+	}
+	if a0 == a1 || b0 == b1 {
+		return
+	}
+
+	if x, y, ok := d.bisect(a0, a1, b0, b1); ok {
+		d.compare(a0, x, b0, y)
+		d.compare(x, a1, y, b1)
+	}
+}
+
+// bisect returns a point of a shortest edit script of a[a0:a1] and b[b0:b1]
+// where the paths searched forward and backward meet, splitting the edit
+// distance in halves; ok is false if the ranges have no line in common.
+func (d *lineDiff) bisect(a0, a1, b0, b1 int) (x, y int, ok bool) {
+	a, b := d.a[a0:a1], d.b[b0:b1]
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset, size := maxD, 2*maxD+2
+
+	d.v1, d.v2 = resize(d.v1, size), resize(d.v2, size)
+	v1, v2 := d.v1, d.v2
+	v1[offset+1], v2[offset+1] = 0, 0
+
+	delta := n - m
+	front := delta%2 != 0 // the forward path meets the backward one if delta is odd
+	var k1start, k1end, k2start, k2end int
+	for e := 0; e < maxD; e++ {
+		// forward
+		for k1 := -e + k1start; k1 <= e-k1end; k1 += 2 {
+			k1offset := offset + k1
+			var x1 int
+			if k1 == -e || (k1 != e && v1[k1offset-1] < v1[k1offset+1]) {
+				x1 = v1[k1offset+1]
+			} else {
+				x1 = v1[k1offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[k1offset] = x1
+
+			switch {
+			case x1 > n:
+				k1end += 2 // off the right of the graph
+			case y1 > m:
+				k1start += 2 // off the bottom of the graph
+			case front:
+				if k2offset := offset + delta - k1; k2offset >= 0 && k2offset < size && v2[k2offset] != -1 {
+					if x1 >= n-v2[k2offset] {
+						return a0 + x1, b0 + y1, true
+					}
+				}
+			}
+		}
+
+		// backward
+		for k2 := -e + k2start; k2 <= e-k2end; k2 += 2 {
+			k2offset := offset + k2
+			var x2 int
+			if k2 == -e || (k2 != e && v2[k2offset-1] < v2[k2offset+1]) {
+				x2 = v2[k2offset+1]
+			} else {
+				x2 = v2[k2offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2offset] = x2
+
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				if k1offset := offset + delta - k2; k1offset >= 0 && k1offset < size && v1[k1offset] != -1 {
+					x1 := v1[k1offset]
+					if x1 >= n-x2 {
+						return a0 + x1, b0 + offset + x1 - k1offset, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// resize returns a slice of n elements set to -1, reusing s if large enough.
+func resize(s []int, n int) []int {
+	if cap(s) < n {
+		s = make([]int, n)
+	}
+	s = s[:n]
+	for i := range s {
+		s[i] = -1
+	}
+	return s
+}
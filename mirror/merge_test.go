@@ -0,0 +1,138 @@
+package mirror
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge3(t *testing.T) {
+	for _, tt := range []struct {
+		name                string
+		base, local, remote string
+		merged              string
+		clean               bool
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\n",
+			local:  "a\nb\n",
+			remote: "a\nb",
+			merged: "a\nb\n",
+			clean:  true,
+		},
+		{
+			name:   "separate changes",
+			base:   "a\nb\nc\nd\n",
+			local:  "A\nb\nc\nd\n",
+			remote: "a\nb\nc\nD\ne\n",
+			merged: "A\nb\nc\nD\ne\n",
+			clean:  true,
+		},
+		{
+			name:   "same change",
+			base:   "a\nb\n",
+			local:  "a\nB\n",
+			remote: "a\nB\n",
+			merged: "a\nB\n",
+			clean:  true,
+		},
+		{
+			name:   "deleted on one side",
+			base:   "a\nb\nc\n",
+			local:  "a\nc\n",
+			remote: "a\nb\nc\nd\n",
+			merged: "a\nc\nd\n",
+			clean:  true,
+		},
+		{
+			name:   "conflict",
+			base:   "a\nb\nc\n",
+			local:  "a\nlocal\nc\n",
+			remote: "a\nremote\nc\n",
+			merged: "a\n<<<<<<< local\nlocal\n||||||| base\nb\n=======\nremote\n>>>>>>> remote\nc\n",
+		},
+		{
+			name:   "added on both sides",
+			local:  "local\n",
+			remote: "remote\n",
+			merged: "<<<<<<< local\nlocal\n||||||| base\n=======\nremote\n>>>>>>> remote\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, clean := merge3(tt.base, tt.local, tt.remote)
+			assert.Equal(t, tt.merged, merged)
+			assert.Equal(t, tt.clean, clean)
+		})
+	}
+}
+
+func TestMatchLines(t *testing.T) {
+	assert.Equal(t, []int{0, -1, 1, 3}, matchLines(
+		[]string{"a", "b", "c", "d"},
+		[]string{"a", "c", "x", "d"},
+	))
+}
+
+func TestMatchLines_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	lines := func() []string {
+		s := make([]string, rng.IntN(30))
+		for i := range s {
+			s[i] = string(rune('a' + rng.IntN(4)))
+		}
+		return s
+	}
+
+	for range 500 {
+		a, b := lines(), lines()
+		match := matchLines(a, b)
+
+		// matched lines are equal, in order, as many as the longest common subsequence
+		matched, last := 0, -1
+		for i, j := range match {
+			if j < 0 {
+				continue
+			}
+			require.Equal(t, a[i], b[j])
+			require.Greater(t, j, last)
+			matched, last = matched+1, j
+		}
+		require.Equal(t, lcsLength(a, b), matched, "%q %q", a, b)
+	}
+}
+
+func lcsLength(a, b []string) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestMatchLines_Large(t *testing.T) {
+	a := make([]string, 200_000)
+	for i := range a {
+		a[i] = fmt.Sprintf("line %d\n", i)
+	}
+	b := slices.Clone(a)
+	b[1000], b[150_000] = "changed\n", "changed\n"
+	b = slices.Insert(b, 100_000, "inserted\n")
+
+	match := matchLines(a, b)
+	assert.Equal(t, -1, match[1000])
+	assert.Equal(t, 100_001, match[100_000])
+	assert.Equal(t, -1, match[150_000])
+	assert.Equal(t, len(b)-1, match[len(a)-1])
+}
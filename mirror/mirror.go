@@ -0,0 +1,142 @@
+// Package mirror mirrors a Yuque repo to a directory of Markdown files.
+//
+// The directory follows the TOC of the repo: TITLE nodes become folders,
+// DOC nodes become Markdown files with YAML front matter, and LINK nodes
+// become link stubs. A node with children becomes a folder, holding the
+// file of the node itself as index.md:
+//
+//	guide/
+//	├── .yuque-mirror.json
+//	├── 入门.md
+//	└── 进阶/
+//	    ├── index.md
+//	    └── 缓存.md
+//
+// The manifest .yuque-mirror.json records the mirrored nodes, so that
+// files of nodes removed from the TOC are cleaned up on the next export.
+//
+// Push goes the other way, publishing a directory with the same layout,
+// written by hand or by Export, to the repo.
+//
+// The manifest also records the state of each doc as of the last sync, so
+// that Export and Push keep the changes made on the other side, and Status
+// and Conflicts report the docs changed on either or both sides.
+//
+// An export WithAssets also downloads the images and attachments of the
+// docs into the assets folder, linked by relative paths.
+package mirror
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flc1125/go-yuque"
+)
+
+const (
+	// indexFile is the file of a node with children, inside its folder.
+	indexFile = "index.md"
+
+	// maxNameLength is the maximum length of a file or folder name in runes, without extension.
+	maxNameLength = 80
+)
+
+// Mirror mirrors a repo to a directory.
+type Mirror struct {
+	client *yuque.Client
+	bookID any
+	dir    string
+	force  bool
+	prune  bool
+
+	// assets is the number of concurrent asset downloads, 0 if disabled
+	assets       int
+	assetClient  *http.Client
+	assetMaxSize int64
+}
+
+// Option configures a Mirror.
+type Option func(*Mirror)
+
+// WithForce makes Export and Push overwrite the changes made on the other
+// side since the last sync, instead of keeping them.
+func WithForce() Option {
+	return func(m *Mirror) {
+		m.force = true
+	}
+}
+
+// WithPrune makes Push remove the TOC nodes not found in the directory from
+// the TOC of the repo, instead of leaving them in place. Their docs are never deleted.
+func WithPrune() Option {
+	return func(m *Mirror) {
+		m.prune = true
+	}
+}
+
+// New returns a mirror of the repo in dir.
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+func New(client *yuque.Client, bookID any, dir string, opts ...Option) *Mirror {
+	m := &Mirror{client: client, bookID: bookID, dir: dir, assetMaxSize: defaultAssetMaxSize}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// names allocates unique names within folders, case insensitively.
+type names map[string]map[string]bool
+
+// unique returns the name in the folder, suffixed by -2, -3... if taken, and reserves it.
+func (n names) unique(dir, name, ext string) string {
+	taken, ok := n[dir]
+	if !ok {
+		taken = make(map[string]bool)
+		n[dir] = taken
+	}
+
+	candidate := name
+	for i := 2; taken[strings.ToLower(candidate+ext)]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	taken[strings.ToLower(candidate+ext)] = true
+
+	return candidate + ext
+}
+
+// reserve marks the name as taken in the folder.
+func (n names) reserve(dir, name string) {
+	n.unique(dir, name, "")
+}
+
+// sanitizeName returns the title as a portable file name, fallback if empty.
+func sanitizeName(title, fallback string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r), unicode.IsControl(r):
+			return '_'
+		}
+		return r
+	}, title)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+
+	if utf8.RuneCountInString(name) > maxNameLength {
+		name = strings.TrimSpace(string([]rune(name)[:maxNameLength]))
+	}
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return path.Join(dir, name)
+}
@@ -0,0 +1,496 @@
+package mirror
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// PushResult reports the docs changed by a push, as slash separated paths
+// relative to the mirror directory.
+type PushResult struct {
+	Created   []string // docs created
+	Updated   []string // docs whose title or body changed
+	Unchanged []string // docs already up to date
+
+	// docs changed in the repo since the last sync, left untouched
+	RemoteChanged []string // only in the repo
+	Conflicted    []string // also in the directory
+
+	// TOC is the plan applied to the TOC of the repo.
+	TOC *yuque.TOCPlan
+}
+
+// localNode is a Markdown file or a folder of the mirror directory.
+type localNode struct {
+	// path is the path of the entry, the folder for TITLE nodes and the
+	// index.md of folders holding one.
+	path     string
+	typ      yuque.TOCType
+	title    string
+	children []*localNode
+
+	// file nodes only
+	hash   string
+	slug   string
+	format yuque.DocFormat
+	body   string
+	url    string
+	doc    *yuque.Doc
+
+	// base is the sync state kept for a doc left untouched
+	base *Entry
+
+	// uuid is the TOC node of a TITLE or LINK node bound by the previous manifest
+	uuid string
+}
+
+// pusher is the state of a push.
+type pusher struct {
+	m        *Mirror
+	book     *yuque.Book
+	previous *Manifest
+	remote   map[string]*yuque.Doc
+	slugs    map[string]string
+	result   *PushResult
+
+	// order and hashes index the entries of the previous manifest by path and by hash
+	order  map[string]int
+	hashes map[string][]*Entry
+}
+
+// newPusher returns a pusher of the mirror to the book, indexing the entries of
+// the previous manifest, which may be nil.
+func newPusher(m *Mirror, book *yuque.Book, previous *Manifest) *pusher {
+	p := &pusher{
+		m:        m,
+		book:     book,
+		previous: previous,
+		slugs:    make(map[string]string),
+		result:   &PushResult{},
+		order:    make(map[string]int),
+		hashes:   make(map[string][]*Entry),
+	}
+	if previous != nil {
+		for i, entry := range previous.Entries {
+			if _, ok := p.order[entry.Path]; !ok {
+				p.order[entry.Path] = i
+			}
+			if entry.Slug != "" {
+				p.hashes[entry.Hash] = append(p.hashes[entry.Hash], entry)
+			}
+		}
+	}
+	return p
+}
+
+// Push creates or updates a doc for each Markdown file of the mirror directory,
+// matched by slug, and reshapes the TOC of the repo after the directory tree.
+//
+// The directory follows the layout written by Export. The slug, title and
+// format of a doc are read from its front matter, falling back to the
+// manifest and then to its path and name; a file with a LINK front matter
+// becomes a link. Folders without index.md become TITLE nodes and folders
+// without any Markdown file are ignored, as are hidden files and folders.
+//
+// Files whose hash did not change since the last export or push are skipped
+// without fetching their doc. Docs changed in the repo since are left
+// untouched, unless the mirror is created WithForce, so that Export can
+// fetch them. TOC nodes not found in the directory are left in place, unless
+// the mirror is created WithPrune.
+//
+// References to the assets downloaded by an export WithAssets are restored
+// to their URLs.
+func (m *Mirror) Push(ctx context.Context) (*PushResult, error) {
+	previous, err := ReadManifest(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	book, _, err := m.client.RepoService.GetRepo(ctx, m.bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newPusher(m, book, previous)
+	nodes, err := p.scan("")
+	if err != nil {
+		return nil, err
+	}
+
+	if p.remote, err = m.remoteDocs(ctx, book.ID); err != nil {
+		return nil, err
+	}
+	if err := p.pushDocs(ctx, nodes); err != nil {
+		return nil, err
+	}
+
+	tocs, _, err := m.client.DocService.GetTOCs(ctx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+	if p.result.TOC, err = yuque.PlanTOC(tocs, p.desired(yuque.NewTOCTree(tocs), nodes)); err != nil {
+		return nil, err
+	}
+	if !m.prune {
+		p.result.TOC.Operations = slices.DeleteFunc(p.result.TOC.Operations, func(op *yuque.TOCOperation) bool {
+			return op.Kind == yuque.TOCOperationRemove
+		})
+	}
+	if !p.result.TOC.Empty() {
+		if tocs, _, err = m.client.DocService.ApplyTOCPlan(ctx, book.ID, p.result.TOC); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &Manifest{BookID: book.ID, Namespace: book.Namespace, Entries: []*Entry{}}
+	if previous != nil {
+		manifest.Assets = previous.Assets
+	}
+	tree := yuque.NewTOCTree(tocs)
+	p.record(manifest, tree, nodes, tree.Roots())
+	if err := manifest.write(m.dir); err != nil {
+		return nil, err
+	}
+	return p.result, nil
+}
+
+// remoteDocs returns the docs of the repo by slug, with their latest version.
+func (m *Mirror) remoteDocs(ctx context.Context, bookID int) (map[string]*yuque.Doc, error) {
+	docs := make(map[string]*yuque.Doc)
+	for doc, err := range m.client.DocService.AllDocs(ctx, bookID, &yuque.GetDocsRequest{OptionalProperties: new("latest_version_id")}) {
+		if err != nil {
+			return nil, err
+		}
+		docs[doc.Slug] = doc
+	}
+	return docs, nil
+}
+
+// scan reads the nodes of the folder, in the order of the previous manifest
+// and then by name.
+func (p *pusher) scan(dir string) ([]*localNode, error) {
+	entries, err := os.ReadDir(filepath.Join(p.m.dir, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*localNode
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || (dir != "" && name == indexFile) {
+			continue
+		}
+
+		var (
+			node *localNode
+			err  error
+		)
+		switch {
+		case e.IsDir():
+			node, err = p.scanFolder(joinPath(dir, name))
+		case e.Type().IsRegular() && strings.EqualFold(path.Ext(name), ".md"):
+			node, err = p.readFile(joinPath(dir, name), strings.TrimSuffix(name, path.Ext(name)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+
+	order := func(n *localNode) int {
+		if i, ok := p.order[n.path]; ok {
+			return i
+		}
+		return len(p.order)
+	}
+	slices.SortStableFunc(nodes, func(a, b *localNode) int { return cmp.Compare(order(a), order(b)) })
+
+	return nodes, nil
+}
+
+// scanFolder reads the folder as a node with children, nil if it holds no Markdown file.
+func (p *pusher) scanFolder(dir string) (*localNode, error) {
+	children, err := p.scan(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	index := joinPath(dir, indexFile)
+	if _, err := os.Stat(filepath.Join(p.m.dir, filepath.FromSlash(index))); err == nil {
+		node, err := p.readFile(index, path.Base(dir))
+		if err != nil {
+			return nil, err
+		}
+		node.children = children
+		return node, nil
+	}
+
+	if len(children) == 0 {
+		return nil, nil
+	}
+	return &localNode{path: dir, typ: yuque.TOCTypeTitle, title: path.Base(dir), children: children}, nil
+}
+
+// readFile reads the Markdown file as a DOC or LINK node, titled name unless its front matter has a title.
+func (p *pusher) readFile(name, title string) (*localNode, error) {
+	data, err := os.ReadFile(filepath.Join(p.m.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	fm, body, err := ParseMarkdown(data)
+	if err != nil {
+		return nil, fmt.Errorf("mirror: %s: %w", name, err)
+	}
+	if fm == nil {
+		fm = &FrontMatter{}
+	}
+
+	node := &localNode{path: name, typ: yuque.TOCTypeDoc, title: cmp.Or(fm.Title, title), hash: hashContent(data)}
+	if fm.Type == yuque.TOCTypeLink {
+		if fm.URL == "" {
+			return nil, fmt.Errorf("mirror: %s: link without url", name)
+		}
+		node.typ, node.url = yuque.TOCTypeLink, fm.URL
+		return node, nil
+	}
+
+	node.slug = fm.Slug
+	if entry := p.previous.entry(name); node.slug == "" && entry != nil {
+		node.slug = entry.Slug
+	}
+	if entry := p.moved(node.hash); node.slug == "" && entry != nil {
+		node.slug = entry.Slug
+	}
+	if node.slug == "" {
+		node.slug = pathSlug(name)
+	}
+	if other, ok := p.slugs[node.slug]; ok {
+		return nil, fmt.Errorf("mirror: %s and %s have the same slug %q", other, name, node.slug)
+	}
+	p.slugs[node.slug] = name
+
+	node.format = cmp.Or(fm.Format, yuque.DocFormatMarkdown)
+	node.body, _ = p.previous.unlocalize(name, string(body))
+	return node, nil
+}
+
+// moved returns the entry of the previous manifest with the hash whose file
+// no longer exists, so that a moved file keeps its doc.
+func (p *pusher) moved(hash string) *Entry {
+	for _, entry := range p.hashes[hash] {
+		if _, err := os.Stat(filepath.Join(p.m.dir, filepath.FromSlash(entry.Path))); errors.Is(err, fs.ErrNotExist) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// pushDocs creates or updates the docs of the nodes, depth first.
+func (p *pusher) pushDocs(ctx context.Context, nodes []*localNode) error {
+	for _, node := range nodes {
+		if node.typ == yuque.TOCTypeDoc {
+			if err := p.pushDoc(ctx, node); err != nil {
+				return fmt.Errorf("mirror: push %s: %w", node.path, err)
+			}
+		}
+		if err := p.pushDocs(ctx, node.children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pusher) pushDoc(ctx context.Context, node *localNode) error {
+	docs := p.m.client.DocService
+
+	remote, ok := p.remote[node.slug]
+	if !ok {
+		doc, _, err := docs.CreateDoc(ctx, p.book.ID, &yuque.CreateDocRequest{
+			Slug:   new(node.slug),
+			Title:  new(node.title),
+			Format: new(node.format),
+			Body:   new(node.body),
+		})
+		if err != nil {
+			return err
+		}
+		node.doc = doc
+		p.result.Created = append(p.result.Created, node.path)
+		return nil
+	}
+
+	if base := p.previous.entry(node.path); base != nil && base.DocID == remote.ID {
+		localChanged := base.Hash != node.hash
+		remoteChanged := base.remoteChanged(remote.LatestVersionID, remote.ContentUpdatedAt)
+		switch {
+		case !localChanged && !remoteChanged:
+			node.doc = remote
+			p.result.Unchanged = append(p.result.Unchanged, node.path)
+			return nil
+		case remoteChanged && !p.m.force:
+			node.doc, node.base = remote, base
+			if localChanged {
+				p.result.Conflicted = append(p.result.Conflicted, node.path)
+			} else {
+				p.result.RemoteChanged = append(p.result.RemoteChanged, node.path)
+			}
+			return nil
+		}
+	}
+
+	doc, _, err := docs.GetDoc(ctx, p.book.ID, remote.ID)
+	if err != nil {
+		return err
+	}
+	if docMatches(doc, node) {
+		node.doc = doc
+		p.result.Unchanged = append(p.result.Unchanged, node.path)
+		return nil
+	}
+
+	if node.doc, _, err = docs.UpdateDoc(ctx, p.book.ID, remote.ID, &yuque.UpdateDocRequest{
+		Title:  new(node.title),
+		Format: new(node.format),
+		Body:   new(node.body),
+	}); err != nil {
+		return err
+	}
+	p.result.Updated = append(p.result.Updated, node.path)
+	return nil
+}
+
+// docMatches reports whether the doc has the title, format and body of the node,
+// ignoring the final newline added by Export.
+func docMatches(doc *yuque.Doc, node *localNode) bool {
+	var body string
+	if doc.Body != nil {
+		body = *doc.Body
+	}
+	format := yuque.DocFormatMarkdown
+	if doc.Format != nil {
+		format = *doc.Format
+	}
+	return doc.Title == node.title && format == node.format &&
+		strings.TrimSuffix(body, "\n") == strings.TrimSuffix(node.body, "\n")
+}
+
+// desired returns the TOC of the nodes, binding TITLE and LINK nodes to the
+// nodes of the previous manifest.
+func (p *pusher) desired(tree *yuque.TOCTree, nodes []*localNode) []*yuque.DesiredTOCNode {
+	desired := make([]*yuque.DesiredTOCNode, 0, len(nodes))
+	for _, node := range nodes {
+		d := &yuque.DesiredTOCNode{Type: node.typ, Children: p.desired(tree, node.children)}
+
+		switch node.typ {
+		case yuque.TOCTypeDoc:
+			// the title of the doc is kept
+			d.DocID = node.doc.ID
+		case yuque.TOCTypeLink, yuque.TOCTypeTitle:
+			d.Title, d.URL = node.title, node.url
+			if entry := p.previous.entry(node.path); entry != nil && entry.Type == node.typ && entry.UUID != "" {
+				d.UUID, node.uuid = entry.UUID, entry.UUID
+
+				// keep titles that are only sanitized in the folder name
+				if current, ok := tree.Node(entry.UUID); ok && node.typ == yuque.TOCTypeTitle &&
+					sanitizeName(current.Title, "untitled") == node.title {
+					d.Title = current.Title
+				}
+			}
+		}
+
+		desired = append(desired, d)
+	}
+	return desired
+}
+
+// record adds the entries of the nodes to the manifest, with the UUIDs of the
+// TOC nodes they were pushed to, among siblings.
+//
+// DOC nodes are matched by doc ID and bound TITLE and LINK nodes by UUID;
+// new TITLE and LINK nodes by type, title and URL.
+func (p *pusher) record(manifest *Manifest, tree *yuque.TOCTree, nodes []*localNode, siblings []*yuque.TOCNode) {
+	var unbound map[string][]*yuque.TOCNode
+	for _, node := range nodes {
+		entry := &Entry{Path: node.path, Type: node.typ, Hash: node.hash}
+		if node.doc != nil {
+			entry.DocID, entry.Slug = node.doc.ID, node.doc.Slug
+			entry.LatestVersionID, entry.ContentUpdatedAt = node.doc.LatestVersionID, node.doc.ContentUpdatedAt
+		}
+		if node.base != nil {
+			entry.keepState(node.base)
+		}
+
+		var toc *yuque.TOCNode
+		switch {
+		case node.doc != nil:
+			toc, _ = tree.NodeByDocID(node.doc.ID)
+		case node.uuid != "":
+			toc, _ = tree.Node(node.uuid)
+		default:
+			if unbound == nil {
+				unbound = make(map[string][]*yuque.TOCNode)
+				for _, sibling := range siblings {
+					key := tocKey(sibling.Type, sibling.Title, sibling.URL)
+					unbound[key] = append(unbound[key], sibling)
+				}
+			}
+			key := tocKey(node.typ, node.title, node.url)
+			if candidates := unbound[key]; len(candidates) > 0 {
+				toc, unbound[key] = candidates[0], candidates[1:]
+			}
+		}
+
+		var children []*yuque.TOCNode
+		if toc != nil && toc.Type == node.typ {
+			entry.UUID, children = toc.UUID, toc.Children
+		}
+
+		manifest.add(entry)
+		p.record(manifest, tree, node.children, children)
+	}
+}
+
+func tocKey(typ yuque.TOCType, title, url string) string {
+	return string(typ) + "\x00" + title + "\x00" + url
+}
+
+// pathSlug returns the slug of a file without one: its path in lowercase
+// with dashes, or md- followed by a hash of the path if it is not ASCII.
+func pathSlug(name string) string {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	name = strings.TrimSuffix(name, "/index")
+
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		case r < 0x80:
+			dash = true
+		default:
+			return "md-" + hashContent([]byte(name))[:8]
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "md-" + hashContent([]byte(name))[:8]
+	}
+	return sb.String()
+}
@@ -0,0 +1,169 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		require.NoError(t, writeFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content)))
+	}
+}
+
+// tocPaths returns the TOC of the repo as type and path of each node, depth first.
+func (r *testRepo) tocPaths() []string {
+	tocs, _, err := r.client.DocService.GetTOCs(ctx, r.book.ID)
+	require.NoError(r.t, err)
+
+	var paths []string
+	for node := range yuque.NewTOCTree(tocs).All() {
+		paths = append(paths, string(node.Type)+" "+node.Path())
+	}
+	return paths
+}
+
+func TestMirror_Push(t *testing.T) {
+	repo := newTestRepo(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"intro.md":             "# Intro\n",
+		"guide/index.md":       "---\ntitle: Guide\nslug: handbook\n---\n# Guide\n",
+		"guide/Install.md":     "# Install\n",
+		"FAQ/入门.md":            "# FAQ\n",
+		"FAQ/Yuque.md":         "---\ntype: LINK\ntitle: Yuque\nurl: https://www.yuque.com\n---\n",
+		"images/logo.png":      "png",
+		".git/HEAD.md":         "hidden",
+		"guide/.draft/next.md": "hidden",
+	})
+
+	mirror := New(repo.client, "yuque/guide", dir)
+	result, err := mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FAQ/入门.md", "guide/index.md", "guide/Install.md", "intro.md"}, result.Created)
+	assert.Empty(t, result.Updated)
+	assert.Len(t, result.TOC.Operations, 6)
+
+	assert.Equal(t, []string{
+		"TITLE FAQ",
+		"LINK FAQ/Yuque",
+		"DOC FAQ/入门",
+		"DOC Guide",
+		"DOC Guide/Install",
+		"DOC intro",
+	}, repo.tocPaths())
+
+	doc, _, err := repo.client.DocService.GetDoc(ctx, repo.book.ID, "handbook")
+	require.NoError(t, err)
+	assert.Equal(t, "Guide", doc.Title)
+	assert.Equal(t, "# Guide\n", *doc.Body)
+	_, _, err = repo.client.DocService.GetDoc(ctx, repo.book.ID, "guide-install")
+	require.NoError(t, err)
+
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, manifest.Entries, 6)
+	assert.Equal(t, "yuque/guide", manifest.Namespace)
+	assert.NotEmpty(t, manifest.Entries[0].UUID)
+	assert.Equal(t, doc.ID, manifest.Entries[3].DocID)
+
+	// nothing changed
+	result, err = mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.Empty(t, result.Updated)
+	assert.Len(t, result.Unchanged, 4)
+	assert.True(t, result.TOC.Empty())
+
+	// change a doc, move another and remove a folder
+	writeFiles(t, dir, map[string]string{"intro.md": "# Intro v2\n"})
+	require.NoError(t, os.Rename(filepath.Join(dir, "guide", "Install.md"), filepath.Join(dir, "Install.md")))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "FAQ")))
+
+	result, err = mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"intro.md"}, result.Updated)
+	assert.Equal(t, []string{"guide/index.md", "Install.md"}, result.Unchanged)
+	// the TOC nodes of the removed folder are left in place
+	assert.Equal(t, []string{"TITLE FAQ", "LINK FAQ/Yuque", "DOC FAQ/入门", "DOC Guide", "DOC intro", "DOC Install"}, repo.tocPaths())
+
+	doc, _, err = repo.client.DocService.GetDoc(ctx, repo.book.ID, "intro")
+	require.NoError(t, err)
+	assert.Equal(t, "# Intro v2\n", *doc.Body)
+
+	// entries are matched to their TOC nodes by doc, not by position
+	tocs, _, err := repo.client.DocService.GetTOCs(ctx, repo.book.ID)
+	require.NoError(t, err)
+	tree := yuque.NewTOCTree(tocs)
+	manifest, err = ReadManifest(dir)
+	require.NoError(t, err)
+	require.Len(t, manifest.Entries, 3)
+	for _, entry := range manifest.Entries {
+		node, ok := tree.NodeByDocID(entry.DocID)
+		require.True(t, ok, entry.Path)
+		assert.Equal(t, node.UUID, entry.UUID, entry.Path)
+	}
+
+	// pruned
+	result, err = New(repo.client, "yuque/guide", dir, WithPrune()).Push(ctx)
+	require.NoError(t, err)
+	assert.Len(t, result.TOC.Operations, 1)
+	assert.Equal(t, []string{"DOC Guide", "DOC intro", "DOC Install"}, repo.tocPaths())
+
+	// their docs are kept
+	docs, _, err := repo.client.DocService.GetDocs(ctx, repo.book.ID, nil)
+	require.NoError(t, err)
+	assert.Len(t, docs.Docs, 4)
+}
+
+func TestMirror_Push_Export(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.addDoc("", "intro", "入门", "# 入门")
+	faq := repo.addTitle("", "FAQ: 常见问题")
+	repo.addDoc(faq, "faq", "问题", "# 问题")
+	repo.addLink(faq, "Yuque", "https://www.yuque.com")
+
+	dir := t.TempDir()
+	mirror := New(repo.client, repo.book.ID, dir)
+	_, err := mirror.Export(ctx)
+	require.NoError(t, err)
+
+	result, err := mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"入门.md", "FAQ_ 常见问题/问题.md"}, result.Unchanged)
+	assert.True(t, result.TOC.Empty(), result.TOC.String())
+
+	// without the manifest, docs are compared with the remote ones
+	require.NoError(t, os.Remove(filepath.Join(dir, ManifestFile)))
+	result, err = mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.Empty(t, result.Updated)
+	assert.Len(t, result.Unchanged, 2)
+}
+
+func TestMirror_Push_DuplicateSlug(t *testing.T) {
+	repo := newTestRepo(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.md": "---\nslug: intro\n---\n",
+		"b.md": "---\nslug: intro\n---\n",
+	})
+
+	_, err := New(repo.client, repo.book.ID, dir).Push(ctx)
+	assert.ErrorContains(t, err, `a.md and b.md have the same slug "intro"`)
+}
+
+func TestPathSlug(t *testing.T) {
+	assert.Equal(t, "getting-started-install", pathSlug("Getting Started/install.md"))
+	assert.Equal(t, "guide", pathSlug("guide/index.md"))
+	assert.Equal(t, "index", pathSlug("index.md"))
+	assert.Regexp(t, `^md-[0-9a-f]{8}$`, pathSlug("入门.md"))
+	assert.NotEqual(t, pathSlug("入门.md"), pathSlug("进阶.md"))
+}
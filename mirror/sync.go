@@ -0,0 +1,262 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// DocState is the state of a doc since the last sync, the last Export or Push.
+type DocState string
+
+const (
+	DocUnchanged     DocState = "unchanged"      // same on both sides
+	DocLocalChanged  DocState = "local-changed"  // changed, added or deleted in the directory only, published by Push
+	DocRemoteChanged DocState = "remote-changed" // changed or added in the repo only, fetched by Export
+	DocConflicted    DocState = "conflicted"     // changed on both sides, see Conflicts
+)
+
+// DocStatus is the sync state of a doc.
+type DocStatus struct {
+	Path  string // file path, empty if the doc is not in the directory
+	DocID int    // doc ID, 0 if the doc is not in the repo
+	Slug  string
+	State DocState
+
+	// base is the entry of the doc as of the last sync, nil if untracked
+	base *Entry
+}
+
+// Status reports the state of the docs of the directory and of the TOC of the
+// repo since the last sync, in the order of the directory and then of the TOC.
+//
+// Changes are detected from the hash of the files and the latest version of
+// the docs recorded in the manifest; docs in the directory and in the repo
+// but not in the manifest are compared by content.
+func (m *Mirror) Status(ctx context.Context) ([]*DocStatus, error) {
+	previous, err := ReadManifest(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("mirror: no %s in %s, export or push first", ManifestFile, m.dir)
+	}
+
+	book, _, err := m.client.RepoService.GetRepo(ctx, m.bookID)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := m.remoteDocs(ctx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+	tocs, _, err := m.client.DocService.GetTOCs(ctx, book.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newPusher(m, book, previous)
+	nodes, err := p.scan("")
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*DocStatus
+	seen := make(map[int]bool)
+	var walk func(nodes []*localNode) error
+	walk = func(nodes []*localNode) error {
+		for _, node := range nodes {
+			if node.typ == yuque.TOCTypeDoc {
+				status, err := p.status(ctx, node, remote[node.slug])
+				if err != nil {
+					return fmt.Errorf("mirror: status %s: %w", node.path, err)
+				}
+				seen[status.DocID] = true
+				statuses = append(statuses, status)
+			}
+			if err := walk(node.children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(nodes); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*yuque.Doc, len(remote))
+	for _, doc := range remote {
+		byID[doc.ID] = doc
+	}
+	for node := range yuque.NewTOCTree(tocs).All() {
+		doc, ok := byID[node.DocID]
+		if node.Type != yuque.TOCTypeDoc || !ok || seen[doc.ID] {
+			continue
+		}
+		seen[doc.ID] = true
+
+		// in the repo only: added there, or deleted from the directory
+		status := &DocStatus{DocID: doc.ID, Slug: doc.Slug, State: DocRemoteChanged}
+		for _, entry := range previous.Entries {
+			if entry.Type == yuque.TOCTypeDoc && entry.DocID == doc.ID {
+				status.Path, status.base, status.State = entry.Path, entry, DocLocalChanged
+				if entry.remoteChanged(doc.LatestVersionID, doc.ContentUpdatedAt) {
+					status.State = DocConflicted
+				}
+				break
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// status returns the state of the doc of the node, remote nil if not in the repo.
+func (p *pusher) status(ctx context.Context, node *localNode, remote *yuque.Doc) (*DocStatus, error) {
+	status := &DocStatus{Path: node.path, Slug: node.slug, State: DocLocalChanged}
+	if remote == nil {
+		return status, nil
+	}
+	status.DocID = remote.ID
+
+	base := p.previous.entry(node.path)
+	if base == nil || base.DocID != remote.ID {
+		base = p.moved(node.hash)
+	}
+	if base != nil && base.DocID == remote.ID {
+		status.base = base
+		status.State = syncState(base.Hash != node.hash, base.remoteChanged(remote.LatestVersionID, remote.ContentUpdatedAt))
+		return status, nil
+	}
+
+	doc, _, err := p.m.client.DocService.GetDoc(ctx, p.book.ID, remote.ID)
+	if err != nil {
+		return nil, err
+	}
+	status.State = DocConflicted
+	if docMatches(doc, node) {
+		status.State = DocUnchanged
+	}
+	return status, nil
+}
+
+func syncState(localChanged, remoteChanged bool) DocState {
+	switch {
+	case localChanged && remoteChanged:
+		return DocConflicted
+	case localChanged:
+		return DocLocalChanged
+	case remoteChanged:
+		return DocRemoteChanged
+	default:
+		return DocUnchanged
+	}
+}
+
+// Conflict is a doc changed on both sides since the last sync.
+type Conflict struct {
+	*DocStatus
+
+	// bodies of the doc as of the last sync, in the directory and in the
+	// repo; Base is empty if unknown and Local if the file was deleted
+	Base, Local, Remote string
+
+	// Merged is the three-way merge of the bodies, with the overlapping
+	// changes between diff3 conflict markers unless Clean.
+	Merged string
+	Clean  bool
+}
+
+// Conflicts returns the docs changed on both sides since the last sync, with
+// a three-way merge of their bodies.
+//
+// The base body is fetched from the version of the doc recorded in the
+// manifest. A conflict is resolved by writing the merged body to the file and
+// pushing it with a mirror created WithForce, or dropped by exporting WithForce.
+func (m *Mirror) Conflicts(ctx context.Context) ([]*Conflict, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadManifest(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []*Conflict
+	for _, status := range statuses {
+		if status.State != DocConflicted {
+			continue
+		}
+
+		c, err := m.conflict(ctx, manifest, status)
+		if err != nil {
+			return nil, fmt.Errorf("mirror: conflict %s: %w", status.Path, err)
+		}
+		conflicts = append(conflicts, c)
+	}
+	return conflicts, nil
+}
+
+func (m *Mirror) conflict(ctx context.Context, manifest *Manifest, status *DocStatus) (*Conflict, error) {
+	c := &Conflict{DocStatus: status}
+
+	if status.base != nil && status.base.LatestVersionID != 0 {
+		version, _, err := m.client.DocService.GetDocVersion(ctx, status.base.LatestVersionID)
+		if err != nil {
+			return nil, err
+		}
+		c.Base = deref(version.Body)
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.dir, filepath.FromSlash(status.Path)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		_, body, err := ParseMarkdown(data)
+		if err != nil {
+			return nil, err
+		}
+		c.Local, _ = manifest.unlocalize(status.Path, string(body))
+	}
+
+	doc, _, err := m.client.DocService.GetDoc(ctx, m.bookID, status.DocID)
+	if err != nil {
+		return nil, err
+	}
+	c.Remote = deref(doc.Body)
+
+	c.Merged, c.Clean = merge3(c.Base, c.Local, c.Remote)
+	return c, nil
+}
+
+// WriteTo writes the conflict as a report headed by the doc, followed by the merged body.
+func (c *Conflict) WriteTo(w io.Writer) (int64, error) {
+	state := "conflicts"
+	if c.Clean {
+		state = "merges cleanly"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "=== %s (doc #%d %s) %s\n", c.Path, c.DocID, c.Slug, state)
+	sb.WriteString(c.Merged)
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,187 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+func (r *testRepo) updateBody(slug, body string) {
+	_, _, err := r.client.DocService.UpdateDoc(ctx, r.book.ID, slug, &yuque.UpdateDocRequest{Body: new(body)})
+	require.NoError(r.t, err)
+}
+
+// editFile replaces old with new in the body of the file.
+func editFile(t *testing.T, dir, name, old, new string) {
+	content := readFile(t, dir, name)
+	require.Contains(t, content, old)
+	writeFiles(t, dir, map[string]string{name: strings.Replace(content, old, new, 1)})
+}
+
+func states(statuses []*DocStatus) map[string]DocState {
+	m := make(map[string]DocState)
+	for _, s := range statuses {
+		m[s.Slug] = s.State
+	}
+	return m
+}
+
+func TestMirror_Sync(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.addDoc("", "intro", "Intro", "# Intro\n")
+	repo.addDoc("", "faq", "FAQ", "# FAQ\n")
+	repo.addDoc("", "guide", "Guide", "# Guide\n\nstep 1\nstep 2\nstep 3\n")
+
+	dir := t.TempDir()
+	mirror := New(repo.client, repo.book.ID, dir)
+	_, err := mirror.Export(ctx)
+	require.NoError(t, err)
+
+	statuses, err := mirror.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]DocState{"intro": DocUnchanged, "faq": DocUnchanged, "guide": DocUnchanged}, states(statuses))
+
+	// edits on both sides
+	editFile(t, dir, "Intro.md", "# Intro", "# Intro v2")
+	repo.updateBody("faq", "# FAQ v2\n")
+	editFile(t, dir, "Guide.md", "step 1", "step one")
+	editFile(t, dir, "Guide.md", "step 2", "step two")
+	repo.updateBody("guide", "# Guide\n\nstep 1\nstep II\nstep 3\n")
+	repo.addDoc("", "news", "News", "# News\n")
+	writeFiles(t, dir, map[string]string{"todo.md": "# TODO\n"})
+
+	statuses, err = mirror.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]DocState{
+		"intro": DocLocalChanged,
+		"faq":   DocRemoteChanged,
+		"guide": DocConflicted,
+		"news":  DocRemoteChanged,
+		"todo":  DocLocalChanged,
+	}, states(statuses))
+
+	conflicts, err := mirror.Conflicts(ctx)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "Guide.md", conflicts[0].Path)
+	assert.False(t, conflicts[0].Clean)
+	assert.Equal(t, "# Guide\n\nstep 1\nstep 2\nstep 3\n", conflicts[0].Base)
+	assert.Equal(t, "# Guide\n\nstep 1\nstep II\nstep 3\n", conflicts[0].Remote)
+
+	var report strings.Builder
+	_, err = conflicts[0].WriteTo(&report)
+	require.NoError(t, err)
+	assert.Equal(t, `=== Guide.md (doc #9 guide) conflicts
+# Guide
+
+<<<<<<< local
+step one
+step two
+||||||| base
+step 1
+step 2
+=======
+step 1
+step II
+>>>>>>> remote
+step 3
+`, report.String())
+
+	// pull keeps the local edits
+	exported, err := mirror.Export(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FAQ.md", "News.md"}, exported.Written)
+	assert.Equal(t, []string{"Intro.md"}, exported.LocalChanged)
+	assert.Equal(t, []string{"Guide.md"}, exported.Conflicted)
+	assert.Contains(t, readFile(t, dir, "Intro.md"), "# Intro v2")
+
+	// push keeps the remote edits
+	pushed, err := mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"todo.md"}, pushed.Created)
+	assert.Equal(t, []string{"Intro.md"}, pushed.Updated)
+	assert.Equal(t, []string{"Guide.md"}, pushed.Conflicted)
+	assert.Empty(t, pushed.RemoteChanged)
+
+	doc, _, err := repo.client.DocService.GetDoc(ctx, repo.book.ID, "guide")
+	require.NoError(t, err)
+	assert.Contains(t, *doc.Body, "step II")
+
+	statuses, err = mirror.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]DocState{
+		"intro": DocUnchanged,
+		"faq":   DocUnchanged,
+		"guide": DocConflicted,
+		"news":  DocUnchanged,
+		"todo":  DocUnchanged,
+	}, states(statuses))
+
+	// resolve the conflict by pushing the merged body
+	editFile(t, dir, "Guide.md", "step two", "step II")
+	_, err = New(repo.client, repo.book.ID, dir, WithForce()).Push(ctx)
+	require.NoError(t, err)
+
+	statuses, err = mirror.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, DocUnchanged, states(statuses)["guide"])
+}
+
+func TestMirror_Sync_RemoteChanged(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.addDoc("", "intro", "Intro", "# Intro\n")
+
+	dir := t.TempDir()
+	mirror := New(repo.client, repo.book.ID, dir)
+	_, err := mirror.Export(ctx)
+	require.NoError(t, err)
+
+	// push does not revert the remote edits, unless forced
+	repo.updateBody("intro", "# Intro v2\n")
+	result, err := mirror.Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Intro.md"}, result.RemoteChanged)
+
+	result, err = New(repo.client, repo.book.ID, dir, WithForce()).Push(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Intro.md"}, result.Updated)
+
+	doc, _, err := repo.client.DocService.GetDoc(ctx, repo.book.ID, "intro")
+	require.NoError(t, err)
+	assert.Equal(t, "# Intro\n", *doc.Body)
+}
+
+func TestMirror_Status_NoManifest(t *testing.T) {
+	repo := newTestRepo(t)
+
+	dir := t.TempDir()
+	_, err := New(repo.client, repo.book.ID, dir).Status(ctx)
+	assert.ErrorContains(t, err, "export or push first")
+	assert.NoFileExists(t, filepath.Join(dir, ManifestFile))
+}
+
+func TestMirror_Status_Moved(t *testing.T) {
+	repo := newTestRepo(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"guide/Install.md": "# Install\n"})
+
+	mirror := New(repo.client, repo.book.ID, dir)
+	_, err := mirror.Push(ctx)
+	require.NoError(t, err)
+
+	// a moved file without a slug keeps its doc
+	require.NoError(t, os.Rename(filepath.Join(dir, "guide", "Install.md"), filepath.Join(dir, "Install.md")))
+
+	statuses, err := mirror.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "Install.md", statuses[0].Path)
+	assert.Equal(t, "guide-install", statuses[0].Slug)
+	assert.Equal(t, DocUnchanged, statuses[0].State)
+}
@@ -0,0 +1,330 @@
+package yuque
+
+import (
+	"context"
+	"iter"
+)
+
+// defaultPageSize 默认每页数量
+//
+// 语雀多数列表接口每页最多 100 条, 且超过 100 条时部分可选字段会失效
+const defaultPageSize = 100
+
+// groupPageSize 团队及成员列表接口不支持 limit, 每页固定 100 条
+const groupPageSize = 100
+
+// searchPageSize 搜索接口不支持 limit, 每页固定 20 条
+const searchPageSize = 20
+
+// statisticPageSize 统计接口每页最多 20 条
+const statisticPageSize = 20
+
+// PageFunc 获取一页数据
+//
+// 返回当前页数据及总数, 总数未知时返回 -1, 此时以空页作为结束标志
+type PageFunc[T any] func(ctx context.Context, offset, limit int) (items []*T, total int, err error)
+
+type PagerOption func(*pagerOptions)
+
+type pagerOptions struct {
+	offset   int
+	pageSize int
+	maxItems int
+}
+
+// WithPagerOffset sets the offset of the first item
+func WithPagerOffset(offset int) PagerOption {
+	return func(o *pagerOptions) {
+		o.offset = offset
+	}
+}
+
+// WithPagerPageSize sets the number of items requested per page
+func WithPagerPageSize(pageSize int) PagerOption {
+	return func(o *pagerOptions) {
+		if pageSize > 0 {
+			o.pageSize = pageSize
+		}
+	}
+}
+
+// WithPagerMaxItems stops the iteration after maxItems items, 0 means no limit
+func WithPagerMaxItems(maxItems int) PagerOption {
+	return func(o *pagerOptions) {
+		o.maxItems = maxItems
+	}
+}
+
+// Paginate 将基于 offset/limit 的列表接口转换为迭代器
+//
+// 迭代在以下情况结束: 数据取完、达到 WithPagerMaxItems 上限、调用方提前 break、
+// 请求出错或 ctx 被取消; 出错时会以 (nil, err) 产出最后一个元素
+func Paginate[T any](ctx context.Context, fetch PageFunc[T], opts ...PagerOption) iter.Seq2[*T, error] {
+	o := pagerOptions{pageSize: defaultPageSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(yield func(*T, error) bool) {
+		offset, count := o.offset, 0
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			items, total, err := fetch(ctx, offset, o.pageSize)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, item := range items {
+				if o.maxItems > 0 && count >= o.maxItems {
+					return
+				}
+				if !yield(item, nil) {
+					return
+				}
+				count++
+			}
+
+			offset += len(items)
+			if len(items) == 0 || (total >= 0 && offset >= total) || (o.maxItems > 0 && count >= o.maxItems) {
+				return
+			}
+		}
+	}
+}
+
+// pagerOptionsFrom 根据请求中的分页参数生成分页选项
+func pagerOptionsFrom(offset, limit *int) []PagerOption {
+	var opts []PagerOption
+	if offset != nil {
+		opts = append(opts, WithPagerOffset(*offset))
+	}
+	if limit != nil {
+		opts = append(opts, WithPagerPageSize(*limit))
+	}
+	return opts
+}
+
+// pageTotal 返回列表响应的总数, 响应缺少 meta 时返回 -1 (未知), 以空页作为结束标志
+func pageTotal(resp *Response) int {
+	if resp.Meta() == nil {
+		return -1
+	}
+	return resp.Total()
+}
+
+// shortPageTotal 返回固定每页数量的列表的总数, 不足一页时即为最后一页, 否则返回 -1 (未知)
+func shortPageTotal(offset, limit, n int) int {
+	if n < limit {
+		return offset + n
+	}
+	return -1
+}
+
+// AllDocs 遍历知识库下的所有文档
+//
+// request 中的 Offset 作为起始偏移量, Limit 作为每页数量
+func (s *docService) AllDocs(ctx context.Context, bookID any, request *GetDocsRequest, opts ...RequestOption) iter.Seq2[*Doc, error] {
+	var r GetDocsRequest
+	if request != nil {
+		r = *request
+	}
+
+	return Paginate(ctx, func(ctx context.Context, offset, limit int) ([]*Doc, int, error) {
+		page := r
+		page.Offset, page.Limit = &offset, &limit
+
+		list, resp, err := s.GetDocs(ctx, bookID, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Docs, pageTotal(resp), nil
+	}, pagerOptionsFrom(r.Offset, r.Limit)...)
+}
+
+// AllUserRepos 遍历用户的所有知识库
+//
+// request 中的 Offset 作为起始偏移量, Limit 作为每页数量
+func (s *repoService) AllUserRepos(ctx context.Context, login any, request *GetReposRequest, opts ...RequestOption) iter.Seq2[*Book, error] {
+	return s.allRepos(ctx, s.GetUserRepos, login, request, opts)
+}
+
+// AllGroupRepos 遍历团队的所有知识库
+//
+// request 中的 Offset 作为起始偏移量, Limit 作为每页数量
+func (s *repoService) AllGroupRepos(ctx context.Context, login any, request *GetReposRequest, opts ...RequestOption) iter.Seq2[*Book, error] {
+	return s.allRepos(ctx, s.GetGroupRepos, login, request, opts)
+}
+
+type getReposFunc func(ctx context.Context, login any, request *GetReposRequest, opts ...RequestOption) (*GetReposResponse, *Response, error)
+
+func (s *repoService) allRepos(ctx context.Context, get getReposFunc, login any, request *GetReposRequest, opts []RequestOption) iter.Seq2[*Book, error] {
+	var r GetReposRequest
+	if request != nil {
+		r = *request
+	}
+
+	return Paginate(ctx, func(ctx context.Context, offset, limit int) ([]*Book, int, error) {
+		page := r
+		page.Offset, page.Limit = &offset, &limit
+
+		list, resp, err := get(ctx, login, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Books, pageTotal(resp), nil
+	}, pagerOptionsFrom(r.Offset, r.Limit)...)
+}
+
+// AllUserGroups 遍历用户的所有团队
+//
+// request 中的 Offset 作为起始偏移量, 返回不足一页时结束
+func (s *groupService) AllUserGroups(ctx context.Context, login any, request *GetUserGroupsRequest, opts ...RequestOption) iter.Seq2[*Group, error] {
+	var r GetUserGroupsRequest
+	if request != nil {
+		r = *request
+	}
+
+	return Paginate(ctx, func(ctx context.Context, offset, limit int) ([]*Group, int, error) {
+		page := r
+		page.Offset = &offset
+
+		groups, _, err := s.GetUserGroups(ctx, login, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return groups, shortPageTotal(offset, limit, len(groups)), nil
+	}, pagerOptionsFrom(r.Offset, new(groupPageSize))...)
+}
+
+// AllGroupMembers 遍历团队的所有成员
+//
+// request 中的 Offset 作为起始偏移量, 返回不足一页时结束
+func (s *groupService) AllGroupMembers(ctx context.Context, login any, request *GetGroupMembersRequest, opts ...RequestOption) iter.Seq2[*GroupMember, error] {
+	var r GetGroupMembersRequest
+	if request != nil {
+		r = *request
+	}
+
+	return Paginate(ctx, func(ctx context.Context, offset, limit int) ([]*GroupMember, int, error) {
+		page := r
+		page.Offset = &offset
+
+		members, _, err := s.GetGroupMembers(ctx, login, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return members, shortPageTotal(offset, limit, len(members)), nil
+	}, pagerOptionsFrom(r.Offset, new(groupPageSize))...)
+}
+
+// AllSearch 遍历搜索的所有结果
+//
+// request 中的 Page 或 Offset 作为起始位置
+func (s *searchService) AllSearch(ctx context.Context, request *SearchRequest, opts ...RequestOption) iter.Seq2[*SearchResult, error] {
+	var r SearchRequest
+	if request != nil {
+		r = *request
+	}
+	if r.Page != nil && *r.Page > 1 {
+		r.Offset = new((*r.Page - 1) * searchPageSize)
+	}
+	r.Page = nil
+
+	return Paginate(ctx, func(ctx context.Context, offset, limit int) ([]*SearchResult, int, error) {
+		page := r
+		page.Offset = &offset
+
+		list, resp, err := s.Search(ctx, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		if total := pageTotal(resp); total >= 0 {
+			return list.Results, total, nil
+		}
+		return list.Results, shortPageTotal(offset, limit, len(list.Results)), nil
+	}, pagerOptionsFrom(r.Offset, new(searchPageSize))...)
+}
+
+// AllMemberStatistics 遍历团队的所有成员统计数据
+//
+// request 中的 Page 作为起始页码, Limit 作为每页数量
+func (s *statisticService) AllMemberStatistics(ctx context.Context, login any, request *GetMemberStatisticsRequest, opts ...RequestOption) iter.Seq2[*MemberStatistic, error] {
+	var r GetMemberStatisticsRequest
+	if request != nil {
+		r = *request
+	}
+
+	return paginateStatistics(ctx, func(ctx context.Context, pageNo, limit int) ([]*MemberStatistic, int, error) {
+		page := r
+		page.Page, page.Limit = &pageNo, &limit
+
+		list, _, err := s.GetMemberStatistics(ctx, login, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Members, list.Total, nil
+	}, r.Page, r.Limit)
+}
+
+// AllBookStatistics 遍历团队的所有知识库统计数据
+//
+// request 中的 Page 作为起始页码, Limit 作为每页数量
+func (s *statisticService) AllBookStatistics(ctx context.Context, login any, request *GetBookStatisticsRequest, opts ...RequestOption) iter.Seq2[*BookStatistic, error] {
+	var r GetBookStatisticsRequest
+	if request != nil {
+		r = *request
+	}
+
+	return paginateStatistics(ctx, func(ctx context.Context, pageNo, limit int) ([]*BookStatistic, int, error) {
+		page := r
+		page.Page, page.Limit = &pageNo, &limit
+
+		list, _, err := s.GetBookStatistics(ctx, login, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Books, list.Total, nil
+	}, r.Page, r.Limit)
+}
+
+// AllDocStatistics 遍历团队的所有文档统计数据
+//
+// request 中的 Page 作为起始页码, Limit 作为每页数量
+func (s *statisticService) AllDocStatistics(ctx context.Context, login any, request *GetDocStatisticsRequest, opts ...RequestOption) iter.Seq2[*DocStatistic, error] {
+	var r GetDocStatisticsRequest
+	if request != nil {
+		r = *request
+	}
+
+	return paginateStatistics(ctx, func(ctx context.Context, pageNo, limit int) ([]*DocStatistic, int, error) {
+		page := r
+		page.Page, page.Limit = &pageNo, &limit
+
+		list, _, err := s.GetDocStatistics(ctx, login, &page, opts...)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Docs, list.Total, nil
+	}, r.Page, r.Limit)
+}
+
+// paginateStatistics 将基于 page/limit 的统计接口转换为迭代器, 每页数量不超过 statisticPageSize
+func paginateStatistics[T any](ctx context.Context, fetch func(ctx context.Context, page, limit int) ([]*T, int, error), page, limit *int) iter.Seq2[*T, error] {
+	size := statisticPageSize
+	if limit != nil && *limit > 0 {
+		size = min(*limit, statisticPageSize)
+	}
+	start := 0
+	if page != nil && *page > 1 {
+		start = (*page - 1) * size
+	}
+
+	return Paginate(ctx, func(ctx context.Context, offset, limit int) ([]*T, int, error) {
+		return fetch(ctx, offset/limit+1, limit)
+	}, WithPagerOffset(start), WithPagerPageSize(size))
+}
@@ -0,0 +1,277 @@
+package yuque
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPageFunc 返回一个包含 n 条数据的分页函数, 并记录每次请求的 offset
+func newTestPageFunc(n, total int, calls *[]int) PageFunc[int] {
+	return func(_ context.Context, offset, limit int) ([]*int, int, error) {
+		*calls = append(*calls, offset)
+
+		var items []*int
+		for i := offset; i < n && i < offset+limit; i++ {
+			items = append(items, new(i))
+		}
+		return items, total, nil
+	}
+}
+
+func collectPage[T any](t *testing.T, seq iter.Seq2[*T, error]) []T {
+	var items []T
+	for item, err := range seq {
+		require.NoError(t, err)
+		items = append(items, *item)
+	}
+	return items
+}
+
+func TestPaginate(t *testing.T) {
+	t.Run("known total", func(t *testing.T) {
+		var calls []int
+		items := collectPage(t, Paginate(ctx, newTestPageFunc(25, 25, &calls), WithPagerPageSize(10)))
+		assert.Len(t, items, 25)
+		assert.Equal(t, 24, items[24])
+		assert.Equal(t, []int{0, 10, 20}, calls)
+	})
+
+	t.Run("exact multiple of page size", func(t *testing.T) {
+		var calls []int
+		items := collectPage(t, Paginate(ctx, newTestPageFunc(20, 20, &calls), WithPagerPageSize(10)))
+		assert.Len(t, items, 20)
+		assert.Equal(t, []int{0, 10}, calls)
+	})
+
+	t.Run("unknown total stops at empty page", func(t *testing.T) {
+		var calls []int
+		items := collectPage(t, Paginate(ctx, newTestPageFunc(25, -1, &calls), WithPagerPageSize(10)))
+		assert.Len(t, items, 25)
+		assert.Equal(t, []int{0, 10, 20, 25}, calls)
+	})
+
+	t.Run("offset and max items", func(t *testing.T) {
+		var calls []int
+		items := collectPage(t, Paginate(ctx, newTestPageFunc(100, 100, &calls),
+			WithPagerOffset(5), WithPagerPageSize(10), WithPagerMaxItems(12)))
+		assert.Equal(t, []int{5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, items)
+		assert.Equal(t, []int{5, 15}, calls)
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		var calls []int
+		var items []int
+		for item, err := range Paginate(ctx, newTestPageFunc(100, 100, &calls), WithPagerPageSize(10)) {
+			require.NoError(t, err)
+			items = append(items, *item)
+			if len(items) == 3 {
+				break
+			}
+		}
+		assert.Equal(t, []int{0, 1, 2}, items)
+		assert.Equal(t, []int{0}, calls)
+	})
+
+	t.Run("fetch error", func(t *testing.T) {
+		fetchErr := errors.New("fetch error")
+		var got []error
+		for item, err := range Paginate(ctx, func(context.Context, int, int) ([]*int, int, error) {
+			return nil, 0, fetchErr
+		}) {
+			assert.Nil(t, item)
+			got = append(got, err)
+		}
+		assert.Equal(t, []error{fetchErr}, got)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var calls []int
+		var errs []error
+		count := 0
+		for _, err := range Paginate(cctx, newTestPageFunc(100, 100, &calls), WithPagerPageSize(10)) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			count++
+			if count == 10 {
+				cancel()
+			}
+		}
+		assert.Equal(t, 10, count)
+		assert.Equal(t, []int{0}, calls)
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], context.Canceled)
+	})
+}
+
+// newTestListHandler 返回分页列出 total 条数据的处理器, withMeta 为 false 时响应不带 meta
+func newTestListHandler(t *testing.T, path string, total int, withMeta bool, offsets *[]int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, path, r.URL.Path)
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		assert.Equal(t, 3, limit)
+		*offsets = append(*offsets, offset)
+
+		items := []map[string]any{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			items = append(items, map[string]any{"id": i + 1})
+		}
+
+		body := map[string]any{"data": items}
+		if withMeta {
+			body["meta"] = map[string]any{"total": total}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+func TestDocService_AllDocs(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		withMeta bool
+		offsets  []int
+	}{
+		{name: "with meta", withMeta: true, offsets: []int{0, 3, 6}},
+		{name: "without meta", withMeta: false, offsets: []int{0, 3, 6, 7}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var offsets []int
+			handler := newTestListHandler(t, "/repos/org/book/docs", 7, tt.withMeta, &offsets)
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "tags", r.URL.Query().Get("optional_properties"))
+				handler.ServeHTTP(w, r)
+			}))
+
+			var ids []int
+			for doc, err := range client.DocService.AllDocs(ctx, "org/book", &GetDocsRequest{
+				Limit:              new(3),
+				OptionalProperties: new("tags"),
+			}) {
+				require.NoError(t, err)
+				ids = append(ids, doc.ID)
+			}
+			assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, ids)
+			assert.Equal(t, tt.offsets, offsets)
+		})
+	}
+}
+
+func TestRepoService_AllGroupRepos_WithoutMeta(t *testing.T) {
+	var offsets []int
+	client := newTestClient(t, newTestListHandler(t, "/groups/yuque/repos", 5, false, &offsets))
+
+	var ids []int
+	for book, err := range client.RepoService.AllGroupRepos(ctx, "yuque", &GetReposRequest{Limit: new(3)}) {
+		require.NoError(t, err)
+		ids = append(ids, book.ID)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+	assert.Equal(t, []int{0, 3, 5}, offsets)
+}
+
+func TestGroupService_AllGroupMembers(t *testing.T) {
+	var offsets []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/groups/yuque/users", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("role"))
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+
+		if r.URL.Query().Get("offset") == "0" {
+			_, _ = w.Write(loadData(t, "internal/testdata/api/group/get_group_members.json"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+
+	var ids []int
+	for member, err := range client.GroupService.AllGroupMembers(ctx, "yuque", &GetGroupMembersRequest{
+		Role: new(GroupMemberRoleMember),
+	}) {
+		require.NoError(t, err)
+		ids = append(ids, member.UserID)
+	}
+	assert.Equal(t, []int{181111, 9111}, ids)
+	// a short page is the last one
+	assert.Equal(t, []string{"0"}, offsets)
+}
+
+func TestGroupService_AllUserGroups(t *testing.T) {
+	var offsets []int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users/yuque/groups", r.URL.Path)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, offset)
+
+		groups := []map[string]any{}
+		for i := offset; i < 130 && i < offset+groupPageSize; i++ {
+			groups = append(groups, map[string]any{"id": i + 1})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": groups})
+	}))
+
+	groups := collectPage(t, client.GroupService.AllUserGroups(ctx, "yuque", nil))
+	assert.Len(t, groups, 130)
+	assert.Equal(t, 130, groups[129].ID)
+	assert.Equal(t, []int{0, 100}, offsets)
+}
+
+func TestSearchService_AllSearch(t *testing.T) {
+	var offsets []int
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "yuque", r.URL.Query().Get("q"))
+		assert.False(t, r.URL.Query().Has("page"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, offset)
+
+		results := []map[string]any{}
+		for i := offset; i < 45 && i < offset+searchPageSize; i++ {
+			results = append(results, map[string]any{"id": i + 1, "type": "doc"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": results, "meta": map[string]any{"total": 45}})
+	}))
+
+	var ids []int
+	for result, err := range client.SearchService.AllSearch(ctx, &SearchRequest{Q: new("yuque"), Page: new(2)}) {
+		require.NoError(t, err)
+		ids = append(ids, result.ID)
+	}
+	assert.Len(t, ids, 25)
+	assert.Equal(t, 21, ids[0])
+	assert.Equal(t, []int{20, 40}, offsets)
+}
+
+func TestStatisticService_AllDocStatistics(t *testing.T) {
+	var pages []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/groups/yuque/statistics/docs", r.URL.Path)
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages = append(pages, r.URL.Query().Get("page"))
+
+		docs := []map[string]any{}
+		for i := (page - 1) * 10; i < 25 && i < page*10; i++ {
+			docs = append(docs, map[string]any{"doc_id": i + 1})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"total": 25, "docs": docs}})
+	}))
+
+	docs := collectPage(t, client.StatisticService.AllDocStatistics(ctx, "yuque", &GetDocStatisticsRequest{Limit: new(10)}))
+	assert.Len(t, docs, 25)
+	assert.Equal(t, 25, docs[24].DocID)
+	assert.Equal(t, []string{"1", "2", "3"}, pages)
+}
@@ -0,0 +1,236 @@
+package yuque
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	// defaultRateLimitInterval 默认令牌生成间隔, 即每秒 100 次请求
+	defaultRateLimitInterval = 10 * time.Millisecond
+
+	// defaultRateLimitBurst 默认令牌桶容量
+	defaultRateLimitBurst = 100
+)
+
+// RateLimiter 客户端限流器, 在每次发送请求前调用, 包括重试
+//
+// 多个 Client 可共享同一个 RateLimiter; 如需在多个进程间共享配额,
+// 可基于 Redis 等外部存储实现该接口
+type RateLimiter interface {
+	// Wait 阻塞直到允许发送下一个请求, ctx 取消时返回 ctx.Err()
+	Wait(ctx context.Context) error
+}
+
+// RateLimitObserver 可选接口, 在每次收到响应后调用, 用于根据服务端返回的剩余配额调整限流
+type RateLimitObserver interface {
+	// Observe 接收响应头中解析出的配额信息
+	Observe(rate *RateLimit)
+}
+
+// TokenBucketRateLimiter 基于令牌桶的限流器
+//
+// 每 interval 生成一个令牌, 最多累积 burst 个; 实现了 RateLimitObserver,
+// 剩余配额不足时会减少可用令牌, 配额耗尽时暂停到重置时间
+type TokenBucketRateLimiter struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+var (
+	_ RateLimiter       = (*TokenBucketRateLimiter)(nil)
+	_ RateLimitObserver = (*TokenBucketRateLimiter)(nil)
+)
+
+// NewTokenBucketRateLimiter 创建令牌桶限流器, 每 interval 生成一个令牌, 桶容量为 burst
+func NewTokenBucketRateLimiter(interval time.Duration, burst int) *TokenBucketRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucketRateLimiter{
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+func (l *TokenBucketRateLimiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		wait, ok := l.reserve(time.Now())
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve 尝试获取一个令牌, 失败时返回需要等待的时间
+func (l *TokenBucketRateLimiter) reserve(now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now), false
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - l.tokens) * float64(l.interval)), false
+}
+
+func (l *TokenBucketRateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		if l.interval <= 0 {
+			l.tokens = l.burst
+		} else {
+			l.tokens = min(l.burst, l.tokens+float64(elapsed)/float64(l.interval))
+		}
+		l.last = now
+	}
+}
+
+func (l *TokenBucketRateLimiter) Observe(rate *RateLimit) {
+	if rate == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	if remaining := float64(rate.Remaining); remaining < l.tokens {
+		l.tokens = max(remaining, 0)
+	}
+
+	if rate.Remaining <= 0 && rate.Reset.After(now) && rate.Reset.After(l.pausedUntil) {
+		l.pausedUntil = rate.Reset
+	}
+}
+
+// rateLimiterKey 请求 context 中限流器的键
+type rateLimiterKey struct{}
+
+// withRateLimiter 返回携带限流器的请求, 由 rateLimitTransport 在每次发送时使用
+func withRateLimiter(req *http.Request, limiter RateLimiter) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), rateLimiterKey{}, limiter))
+}
+
+// rateLimitTransport 在每次发送请求前等待请求 context 中的限流器, 收到响应后通知配额信息
+//
+// NewRetryableHTTPClient 将其置于重试之内, 因此每次重试都会被限流并观察到 429 响应
+type rateLimitTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	limiter, _ := req.Context().Value(rateLimiterKey{}).(RateLimiter)
+	if limiter == nil {
+		return base.RoundTrip(req)
+	}
+
+	if err := limiter.Wait(req.Context()); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err == nil {
+		observeRateLimit(limiter, resp)
+	}
+	return resp, err
+}
+
+// withRateLimitTransport 返回经过 rateLimitTransport 发送请求的 HTTP 客户端
+//
+// NewRetryableHTTPClient 创建的客户端已在重试之内限流, 原样返回; 其他客户端
+// 复制后在最外层限流, 即每次 Client.Do 限流一次
+func withRateLimitTransport(hc *http.Client) *http.Client {
+	if rt, ok := hc.Transport.(*retryablehttp.RoundTripper); ok && rt.Client != nil && rt.Client.HTTPClient != nil {
+		if _, ok := rt.Client.HTTPClient.Transport.(*rateLimitTransport); ok {
+			return hc
+		}
+	}
+
+	clone := *hc
+	clone.Transport = &rateLimitTransport{base: hc.Transport}
+	return &clone
+}
+
+// observeRateLimit 将响应中的配额信息通知给限流器
+//
+// 429 响应未携带配额头时, 以 Retry-After 作为配额重置时间
+func observeRateLimit(limiter RateLimiter, resp *http.Response) {
+	observer, ok := limiter.(RateLimitObserver)
+	if !ok {
+		return
+	}
+
+	rate := parseRateLimit(resp.Header)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if rate == nil {
+			rate = &RateLimit{}
+		}
+		rate.Remaining = 0
+		if reset, ok := parseRetryAfter(resp.Header, time.Now()); ok {
+			rate.Reset = reset
+		}
+	}
+
+	if rate != nil {
+		observer.Observe(rate)
+	}
+}
+
+// parseRetryAfter 解析 Retry-After 头, 支持秒数及 HTTP 日期两种格式
+func parseRetryAfter(header http.Header, now time.Time) (time.Time, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return t, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,192 @@
+package yuque
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketRateLimiter_Wait(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(50*time.Millisecond, 2)
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(ctx))
+	require.NoError(t, limiter.Wait(ctx))
+	assert.Less(t, time.Since(start), 40*time.Millisecond)
+
+	require.NoError(t, limiter.Wait(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestTokenBucketRateLimiter_WaitCanceled(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(time.Hour, 1)
+	require.NoError(t, limiter.Wait(ctx))
+
+	cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	err := limiter.Wait(cctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTokenBucketRateLimiter_Observe(t *testing.T) {
+	t.Run("remaining budget caps tokens", func(t *testing.T) {
+		limiter := NewTokenBucketRateLimiter(time.Hour, 10)
+		limiter.Observe(&RateLimit{Limit: 5000, Remaining: 1})
+
+		require.NoError(t, limiter.Wait(ctx))
+
+		cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, limiter.Wait(cctx), context.DeadlineExceeded)
+	})
+
+	t.Run("exhausted budget pauses until reset", func(t *testing.T) {
+		limiter := NewTokenBucketRateLimiter(time.Millisecond, 10)
+		limiter.Observe(&RateLimit{Limit: 5000, Remaining: 0, Reset: time.Now().Add(60 * time.Millisecond)})
+
+		start := time.Now()
+		require.NoError(t, limiter.Wait(ctx))
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	header := make(http.Header)
+	_, ok := parseRetryAfter(header, now)
+	assert.False(t, ok)
+
+	header.Set("Retry-After", "30")
+	reset, ok := parseRetryAfter(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(30*time.Second), reset)
+
+	header.Set("Retry-After", "Sat, 01 Mar 2025 00:01:00 GMT")
+	reset, ok = parseRetryAfter(header, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Minute), reset.UTC())
+
+	header.Set("Retry-After", "soon")
+	_, ok = parseRetryAfter(header, now)
+	assert.False(t, ok)
+}
+
+type testRateLimiter struct {
+	mu       sync.Mutex
+	waits    int
+	observed []*RateLimit
+}
+
+func (l *testRateLimiter) Wait(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waits++
+	return nil
+}
+
+func (l *testRateLimiter) Observe(rate *RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.observed = append(l.observed, rate)
+}
+
+func TestClient_WithRateLimiter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/throttled" {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"status": 429, "message": "too many requests"}`))
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		_, _ = w.Write([]byte(successResponse))
+	}))
+	t.Cleanup(srv.Close)
+
+	limiter := &testRateLimiter{}
+	client, err := NewClient(apiToken, WithBaseURL(srv.URL), WithHTTPClient(srv.Client()), WithRateLimiter(limiter))
+	require.NoError(t, err)
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "ok", nil, nil)
+	require.NoError(t, err)
+	_, err = client.Do(req, nil)
+	require.NoError(t, err)
+
+	req, err = client.NewRequest(ctx, http.MethodGet, "throttled", nil, nil)
+	require.NoError(t, err)
+	_, err = client.Do(req, nil)
+	require.Error(t, err)
+
+	assert.Equal(t, 2, limiter.waits)
+	require.Len(t, limiter.observed, 2)
+	assert.Equal(t, &RateLimit{Limit: 5000, Remaining: 4321}, limiter.observed[0])
+	assert.Zero(t, limiter.observed[1].Remaining)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), limiter.observed[1].Reset, 5*time.Second)
+}
+
+func TestClient_WithRateLimiter_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"status": 429, "message": "too many requests"}`))
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		_, _ = w.Write([]byte(successResponse))
+	}))
+	t.Cleanup(srv.Close)
+
+	limiter := &testRateLimiter{}
+	client, err := NewClient(apiToken, WithBaseURL(srv.URL), WithRateLimiter(limiter), WithHTTPClient(NewRetryableHTTPClient(
+		WithRetryableHTTPClientRetryWaitMin(time.Millisecond),
+		WithRetryableHTTPClientRetryWaitMax(time.Millisecond),
+	)))
+	require.NoError(t, err)
+
+	req, err := client.NewRequest(ctx, http.MethodGet, "ok", nil, nil)
+	require.NoError(t, err)
+	_, err = client.Do(req, nil)
+	require.NoError(t, err)
+
+	// each attempt is limited and observed
+	assert.Equal(t, 3, limiter.waits)
+	require.Len(t, limiter.observed, 3)
+	assert.Zero(t, limiter.observed[0].Remaining)
+	assert.Zero(t, limiter.observed[1].Remaining)
+	assert.Equal(t, &RateLimit{Limit: 5000, Remaining: 4321}, limiter.observed[2])
+}
+
+func TestClient_WithRateLimiter_KeepsHTTPClient(t *testing.T) {
+	hc := &http.Client{}
+	client, err := NewClient(apiToken, WithHTTPClient(hc))
+	require.NoError(t, err)
+	assert.Nil(t, hc.Transport)
+	assert.IsType(t, &rateLimitTransport{}, client.httpClient.Transport)
+
+	client, err = NewClient(apiToken, WithHTTPClient(hc), WithRateLimiter(nil))
+	require.NoError(t, err)
+	assert.Same(t, hc, client.httpClient)
+}
+
+func TestClient_WithRateLimiterDisabled(t *testing.T) {
+	client, err := NewClient(apiToken, WithRateLimiter(nil))
+	require.NoError(t, err)
+	assert.Nil(t, client.rateLimiter)
+
+	client, err = NewClient(apiToken)
+	require.NoError(t, err)
+	assert.IsType(t, &TokenBucketRateLimiter{}, client.rateLimiter)
+}
@@ -2,6 +2,7 @@ package yuque
 
 import (
 	"net/http"
+	"net/url"
 )
 
 type RequestOption func(*http.Request) error
@@ -42,3 +43,16 @@ func WithRequestUserAgent(userAgent string) RequestOption {
 		return nil
 	}
 }
+
+func WithRequestQuery(values url.Values) RequestOption {
+	return func(req *http.Request) error {
+		q := req.URL.Query()
+		for k, vs := range values {
+			for _, v := range vs {
+				q.Add(k, v)
+			}
+		}
+		req.URL.RawQuery = q.Encode()
+		return nil
+	}
+}
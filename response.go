@@ -4,7 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+	headerRequestID          = "X-Request-Id"
+	headerTraceID            = "EagleEye-TraceId"
 )
 
 // Response represents an API response.
@@ -13,35 +25,134 @@ type Response struct {
 	rawBody *RawBody
 }
 
-func (r *Response) meta() *Meta {
-	if r.rawBody == nil {
+// Meta returns the meta of the response body, nil if absent.
+func (r *Response) Meta() *Meta {
+	if r == nil || r.rawBody == nil {
 		return nil
 	}
 	return r.rawBody.Meta
 }
 
+// Total returns the total count of a list response, 0 if absent.
+func (r *Response) Total() int {
+	if meta := r.Meta(); meta != nil {
+		return meta.Total
+	}
+	return 0
+}
+
+// Data returns the raw JSON of the data field.
+//
+// The data field is streamed straight into the value passed to Client.Do, so
+// Data returns nil unless the value is nil or the client is created
+// WithKeepResponseData.
+func (r *Response) Data() json.RawMessage {
+	if r == nil || r.rawBody == nil {
+		return nil
+	}
+	return r.rawBody.Data
+}
+
+// RateLimit returns the rate limit parsed from the response headers, nil if absent.
+func (r *Response) RateLimit() *RateLimit {
+	if r == nil || r.Response == nil {
+		return nil
+	}
+	return parseRateLimit(r.Header)
+}
+
+// RequestID returns the request ID assigned by the server, used for troubleshooting with Yuque.
+func (r *Response) RequestID() string {
+	if r == nil || r.Response == nil {
+		return ""
+	}
+	return parseRequestID(r.Header)
+}
+
 type Meta struct {
 	Total int `json:"total,omitempty"`
 }
 
+// RateLimit represents the rate limit budget of the token.
+type RateLimit struct {
+	Limit     int       // 周期内的请求配额
+	Remaining int       // 周期内剩余的请求次数
+	Reset     time.Time // 配额重置时间, 未返回时为零值
+}
+
+func parseRateLimit(header http.Header) *RateLimit {
+	limit, err := strconv.Atoi(header.Get(headerRateLimitLimit))
+	if err != nil {
+		return nil
+	}
+
+	remaining, err := strconv.Atoi(header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return nil
+	}
+
+	rate := &RateLimit{Limit: limit, Remaining: remaining}
+	if reset, err := strconv.ParseInt(header.Get(headerRateLimitReset), 10, 64); err == nil && reset > 0 {
+		rate.Reset = time.Unix(reset, 0)
+	}
+
+	return rate
+}
+
+func parseRequestID(header http.Header) string {
+	if id := header.Get(headerRequestID); id != "" {
+		return id
+	}
+	return header.Get(headerTraceID)
+}
+
 // RawBody represents a raw body.
 type RawBody struct {
-	Status  int             `json:"status,omitempty"`
-	Meta    *Meta           `json:"meta,omitempty"`
-	Data    json.RawMessage `json:"data,omitempty"`
-	Message string          `json:"info,omitempty"`
+	Status       int             `json:"status,omitempty"`
+	Meta         *Meta           `json:"meta,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+	Message      string          `json:"info,omitempty"`
+	ErrorMessage string          `json:"message,omitempty"` // 错误信息
+	Errors       json.RawMessage `json:"errors,omitempty"`  // 字段校验错误
+}
+
+var (
+	ErrBadRequest   = errors.New("yuque: bad request")           // 400
+	ErrUnauthorized = errors.New("yuque: unauthorized")          // 401
+	ErrForbidden    = errors.New("yuque: forbidden")             // 403
+	ErrNotFound     = errors.New("yuque: not found")             // 404
+	ErrValidation   = errors.New("yuque: validation failed")     // 422, 或 400 且包含字段校验错误
+	ErrRateLimited  = errors.New("yuque: rate limited")          // 429
+	ErrServerError  = errors.New("yuque: internal server error") // 5xx
+)
+
+// FieldError represents a field-level validation error.
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
 }
 
 // ErrorResponse represents a yuque error response.
+//
+// Use errors.Is with ErrNotFound, ErrUnauthorized etc. to check the kind of error.
 type ErrorResponse struct {
 	response *http.Response
 	rawBody  *RawBody
+	body     []byte
 	err      error
 }
 
 func (e *ErrorResponse) Error() string {
 	if e.rawBody != nil {
-		return fmt.Sprintf("code: %d, info: %s", e.rawBody.Status, e.rawBody.Message)
+		return fmt.Sprintf("code: %d, info: %s", e.rawBody.Status, e.Message())
 	}
 
 	if e.response != nil {
@@ -55,6 +166,115 @@ func (e *ErrorResponse) Unwrap() error {
 	return e.err
 }
 
+// Is reports whether the error matches one of the sentinel errors, such as ErrNotFound.
+func (e *ErrorResponse) Is(target error) bool {
+	status := e.StatusCode()
+	if status == 0 {
+		status = e.Code()
+	}
+
+	switch target {
+	case ErrBadRequest:
+		return status == http.StatusBadRequest
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized
+	case ErrForbidden:
+		return status == http.StatusForbidden
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	case ErrValidation:
+		return status == http.StatusUnprocessableEntity ||
+			(status == http.StatusBadRequest && len(e.FieldErrors()) > 0)
+	case ErrRateLimited:
+		return status == http.StatusTooManyRequests
+	case ErrServerError:
+		return status >= http.StatusInternalServerError && status <= 599
+	}
+
+	return false
+}
+
+// Response returns the HTTP response, nil if absent.
+func (e *ErrorResponse) Response() *http.Response {
+	return e.response
+}
+
+// StatusCode returns the HTTP status code, 0 if absent.
+func (e *ErrorResponse) StatusCode() int {
+	if e.response == nil {
+		return 0
+	}
+	return e.response.StatusCode
+}
+
+// Body returns the leading bytes of the raw response body, capped at 1 KiB.
+func (e *ErrorResponse) Body() []byte {
+	return e.body
+}
+
+// Code returns the yuque status code of the response body, 0 if absent.
+func (e *ErrorResponse) Code() int {
+	if e.rawBody == nil {
+		return 0
+	}
+	return e.rawBody.Status
+}
+
+// Message returns the error message of the response body.
+func (e *ErrorResponse) Message() string {
+	if e.rawBody == nil {
+		return ""
+	}
+	if e.rawBody.ErrorMessage != "" {
+		return e.rawBody.ErrorMessage
+	}
+	return e.rawBody.Message
+}
+
+// RequestID returns the request ID assigned by the server, used for troubleshooting with Yuque.
+func (e *ErrorResponse) RequestID() string {
+	if e.response == nil {
+		return ""
+	}
+	return parseRequestID(e.response.Header)
+}
+
+// FieldErrors returns the field-level validation errors of the response body.
+//
+// Both a list of {field, code, message} objects and a map of field to messages are supported.
+func (e *ErrorResponse) FieldErrors() []*FieldError {
+	if e.rawBody == nil || len(e.rawBody.Errors) == 0 {
+		return nil
+	}
+
+	var list []*FieldError
+	if err := json.Unmarshal(e.rawBody.Errors, &list); err == nil {
+		return list
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(e.rawBody.Errors, &fields); err != nil {
+		return nil
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(fields)) {
+		var messages []string
+		if err := json.Unmarshal(fields[field], &messages); err != nil {
+			var message string
+			if err := json.Unmarshal(fields[field], &message); err != nil {
+				continue
+			}
+			messages = []string{message}
+		}
+
+		for _, message := range messages {
+			list = append(list, &FieldError{Field: field, Message: message})
+		}
+	}
+
+	return list
+}
+
 func IsErrorResponse(err error) bool {
 	var e *ErrorResponse
 	return errors.As(err, &e)
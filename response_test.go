@@ -1,11 +1,17 @@
 package yuque
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResponse_ErrorResponse(t *testing.T) {
@@ -31,3 +37,153 @@ func TestResponse_ErrorResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestResponse_Metadata(t *testing.T) {
+	header := make(http.Header)
+	header.Set("X-RateLimit-Limit", "5000")
+	header.Set("X-RateLimit-Remaining", "4998")
+	header.Set("X-RateLimit-Reset", "1740787200")
+	header.Set("X-Request-Id", "0b1e2a3c17407872001234567")
+
+	resp := &Response{
+		Response: &http.Response{StatusCode: http.StatusOK, Header: header},
+		rawBody: &RawBody{
+			Meta: &Meta{Total: 53},
+			Data: json.RawMessage(`[{"id":1}]`),
+		},
+	}
+
+	assert.Equal(t, 53, resp.Total())
+	assert.Equal(t, &Meta{Total: 53}, resp.Meta())
+	assert.JSONEq(t, `[{"id":1}]`, string(resp.Data()))
+	assert.Equal(t, &RateLimit{
+		Limit:     5000,
+		Remaining: 4998,
+		Reset:     time.Unix(1740787200, 0),
+	}, resp.RateLimit())
+	assert.Equal(t, "0b1e2a3c17407872001234567", resp.RequestID())
+}
+
+func TestResponse_MetadataAbsent(t *testing.T) {
+	header := make(http.Header)
+	header.Set("EagleEye-TraceId", "trace-id")
+	header.Set("X-RateLimit-Limit", "5000")
+
+	resp := &Response{Response: &http.Response{StatusCode: http.StatusOK, Header: header}}
+	assert.Nil(t, resp.Meta())
+	assert.Zero(t, resp.Total())
+	assert.Nil(t, resp.Data())
+	assert.Nil(t, resp.RateLimit())
+	assert.Equal(t, "trace-id", resp.RequestID())
+
+	var nilResp *Response
+	assert.Zero(t, nilResp.Total())
+	assert.Nil(t, nilResp.RateLimit())
+	assert.Empty(t, nilResp.RequestID())
+}
+
+func TestErrorResponse_Is(t *testing.T) {
+	sentinels := []error{ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrValidation, ErrRateLimited, ErrServerError}
+
+	tests := []struct {
+		name string
+		err  *ErrorResponse
+		want []error
+	}{
+		{"400", &ErrorResponse{response: &http.Response{StatusCode: 400}}, []error{ErrBadRequest}},
+		{"400 with field errors", &ErrorResponse{response: &http.Response{StatusCode: 400}, rawBody: &RawBody{Errors: json.RawMessage(`[{"field":"slug"}]`)}}, []error{ErrBadRequest, ErrValidation}}, //nolint:lll
+		{"401", &ErrorResponse{response: &http.Response{StatusCode: 401}}, []error{ErrUnauthorized}},
+		{"403", &ErrorResponse{response: &http.Response{StatusCode: 403}}, []error{ErrForbidden}},
+		{"404", &ErrorResponse{response: &http.Response{StatusCode: 404}}, []error{ErrNotFound}},
+		{"422", &ErrorResponse{response: &http.Response{StatusCode: 422}}, []error{ErrValidation}},
+		{"429", &ErrorResponse{response: &http.Response{StatusCode: 429}}, []error{ErrRateLimited}},
+		{"502", &ErrorResponse{response: &http.Response{StatusCode: 502}}, []error{ErrServerError}},
+		{"status from raw body", &ErrorResponse{rawBody: &RawBody{Status: 404}}, []error{ErrNotFound}},
+		{"no status", &ErrorResponse{err: errors.New("error")}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, sentinel := range sentinels {
+				var err error = tt.err
+				assert.Equal(t, slices.Contains(tt.want, sentinel), errors.Is(err, sentinel), sentinel)
+				assert.Equal(t, slices.Contains(tt.want, sentinel), errors.Is(fmt.Errorf("wrapped: %w", err), sentinel), sentinel)
+			}
+		})
+	}
+}
+
+func TestErrorResponse_Is_RetryableHTTPClient(t *testing.T) {
+	for _, tt := range []struct {
+		status int
+		want   error
+		other  error
+	}{
+		{status: http.StatusTooManyRequests, want: ErrRateLimited, other: ErrServerError},
+		{status: http.StatusServiceUnavailable, want: ErrServerError, other: ErrRateLimited},
+	} {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+
+			client, err := NewClient(apiToken, WithBaseURL(srv.URL), WithHTTPClient(NewRetryableHTTPClient(
+				WithRetryableHTTPClientRetryMax(1),
+				WithRetryableHTTPClientRetryWaitMin(time.Millisecond),
+				WithRetryableHTTPClientRetryWaitMax(time.Millisecond),
+			)))
+			require.NoError(t, err)
+
+			_, _, err = client.UserService.GetUser(ctx)
+			assert.ErrorIs(t, err, tt.want)
+			assert.NotErrorIs(t, err, tt.other)
+		})
+	}
+}
+
+func TestErrorResponse_Fields(t *testing.T) {
+	header := make(http.Header)
+	header.Set("X-Request-Id", "request-id")
+
+	err := &ErrorResponse{
+		response: &http.Response{StatusCode: http.StatusNotFound, Header: header},
+		rawBody:  &RawBody{Status: 404, ErrorMessage: "Not Found"},
+	}
+
+	assert.Equal(t, http.StatusNotFound, err.StatusCode())
+	assert.Equal(t, 404, err.Code())
+	assert.Equal(t, "Not Found", err.Message())
+	assert.Equal(t, "request-id", err.RequestID())
+	assert.Equal(t, "code: 404, info: Not Found", err.Error())
+	assert.Nil(t, err.FieldErrors())
+
+	empty := &ErrorResponse{err: errors.New("error")}
+	assert.Zero(t, empty.StatusCode())
+	assert.Zero(t, empty.Code())
+	assert.Empty(t, empty.Message())
+	assert.Empty(t, empty.RequestID())
+	assert.Nil(t, empty.Response())
+}
+
+func TestErrorResponse_FieldErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors string
+		want   []*FieldError
+	}{
+		{"list", `[{"field":"slug","code":"taken","message":"已被占用"}]`, []*FieldError{{Field: "slug", Code: "taken", Message: "已被占用"}}},                                                  //nolint:lll
+		{"map of lists", `{"title":["不能为空"],"slug":["格式错误","过长"]}`, []*FieldError{{Field: "slug", Message: "格式错误"}, {Field: "slug", Message: "过长"}, {Field: "title", Message: "不能为空"}}}, //nolint:lll
+		{"map of strings", `{"name":"不能为空"}`, []*FieldError{{Field: "name", Message: "不能为空"}}},
+		{"invalid", `"oops"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &ErrorResponse{rawBody: &RawBody{Errors: json.RawMessage(tt.errors)}}
+			assert.Equal(t, tt.want, err.FieldErrors())
+		})
+	}
+
+	assert.Equal(t, "slug: 已被占用", (&FieldError{Field: "slug", Message: "已被占用"}).Error())
+}
@@ -0,0 +1,625 @@
+package yuque
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DesiredTOCNode 期望的目录节点, 可直接由 JSON/YAML 反序列化得到
+//
+// 节点类型未指定时: 有 DocID 的为 DOC, 有 URL 的为 LINK, 否则为 TITLE
+type DesiredTOCNode struct {
+	UUID       string            `json:"uuid,omitempty" yaml:"uuid,omitempty"`               // 绑定的现有节点 UUID [可选]
+	Type       TOCType           `json:"type,omitempty" yaml:"type,omitempty"`               // 节点类型 (DOC:文档, LINK:外链, TITLE:分组)
+	Title      string            `json:"title,omitempty" yaml:"title,omitempty"`             // 节点名称, DOC 节点为空时沿用现有名称
+	DocID      int               `json:"doc_id,omitempty" yaml:"doc_id,omitempty"`           // 文档 ID [DOC 节点必填]
+	URL        string            `json:"url,omitempty" yaml:"url,omitempty"`                 // 节点 URL [LINK 节点必填]
+	OpenWindow *int              `json:"open_window,omitempty" yaml:"open_window,omitempty"` // 是否在新窗口打开 (0:当前页打开, 1:新窗口打开)
+	Visible    *int              `json:"visible,omitempty" yaml:"visible,omitempty"`         // 是否可见 (0:不可见, 1:可见)
+	Children   []*DesiredTOCNode `json:"children,omitempty" yaml:"children,omitempty"`       // 子节点
+}
+
+func (n *DesiredTOCNode) nodeType() TOCType {
+	switch {
+	case n.Type != "":
+		return n.Type
+	case n.DocID > 0:
+		return TOCTypeDoc
+	case n.URL != "":
+		return TOCTypeLink
+	default:
+		return TOCTypeTitle
+	}
+}
+
+func (n *DesiredTOCNode) String() string {
+	switch t := n.nodeType(); t {
+	case TOCTypeDoc:
+		if n.Title == "" {
+			return fmt.Sprintf("%s #%d", t, n.DocID)
+		}
+		return fmt.Sprintf("%s %q (#%d)", t, n.Title, n.DocID)
+	case TOCTypeLink:
+		return fmt.Sprintf("%s %q (%s)", t, n.Title, n.URL)
+	default:
+		return fmt.Sprintf("%s %q", t, n.Title)
+	}
+}
+
+// TOCOperationKind 目录变更类型
+type TOCOperationKind string
+
+const (
+	TOCOperationInsert TOCOperationKind = "insert" // 插入新节点
+	TOCOperationMove   TOCOperationKind = "move"   // 移动现有节点
+	TOCOperationEdit   TOCOperationKind = "edit"   // 编辑现有节点
+	TOCOperationRemove TOCOperationKind = "remove" // 删除现有节点及其子节点
+)
+
+// TOCOperation 单个目录变更
+type TOCOperation struct {
+	Kind TOCOperationKind
+
+	// Node 期望节点 [insert/move/edit]
+	Node *DesiredTOCNode
+
+	// Current 现有节点 [move/edit/remove]
+	Current *TOC
+
+	// Parent 期望的父节点, nil 表示根节点 [insert/move]
+	Parent *DesiredTOCNode
+
+	// After 期望的前一个同级节点, nil 表示作为第一个子节点 [insert/move]
+	After *DesiredTOCNode
+}
+
+func (op *TOCOperation) String() string {
+	position := func() string {
+		var parent string
+		if op.Parent != nil {
+			parent = " under " + op.Parent.String()
+		}
+		if op.After == nil {
+			return "first" + parent
+		}
+		return "after " + op.After.String() + parent
+	}
+
+	switch op.Kind {
+	case TOCOperationInsert:
+		return fmt.Sprintf("insert %s %s", op.Node, position())
+	case TOCOperationMove:
+		return fmt.Sprintf("move %s [%s] %s", op.Node, op.Current.UUID, position())
+	case TOCOperationEdit:
+		return fmt.Sprintf("edit %s [%s] -> %s", op.Current.Type, op.Current.UUID, op.Node)
+	case TOCOperationRemove:
+		return fmt.Sprintf("remove %s %q [%s]", op.Current.Type, op.Current.Title, op.Current.UUID)
+	}
+	return string(op.Kind)
+}
+
+// TOCPlan 将现有目录调整为期望目录所需的变更
+//
+// 变更按安全顺序排列: 先按深度优先顺序插入/移动节点, 再编辑节点, 最后删除多余节点,
+// 保证删除时不会误删仍需保留的子节点
+type TOCPlan struct {
+	Operations []*TOCOperation
+
+	// matched 期望节点与现有节点 UUID 的对应关系
+	matched map[*DesiredTOCNode]string
+
+	// existing 现有目录中的所有节点 UUID
+	existing []string
+}
+
+// Empty 返回是否无需变更
+func (p *TOCPlan) Empty() bool {
+	return len(p.Operations) == 0
+}
+
+func (p *TOCPlan) String() string {
+	var sb strings.Builder
+	_, _ = p.WriteTo(&sb)
+	return sb.String()
+}
+
+// WriteTo 逐行输出变更计划, 可用于 dry-run
+func (p *TOCPlan) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for i, op := range p.Operations {
+		n, err := fmt.Fprintf(w, "%d. %s\n", i+1, op)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// PlanTOC 计算将现有目录 current 调整为期望目录 desired 所需的最少变更
+//
+// 期望节点与现有节点的匹配规则: 优先按 UUID, DOC 节点按 DocID, 其余节点按类型及标题路径
+func PlanTOC(current []*TOC, desired []*DesiredTOCNode) (*TOCPlan, error) {
+	if err := validateDesiredTOC(desired); err != nil {
+		return nil, err
+	}
+
+	tree := NewTOCTree(current)
+	p := &tocPlanner{
+		plan: &TOCPlan{matched: make(map[*DesiredTOCNode]string)},
+		sims: make(map[*DesiredTOCNode]*tocSimNode),
+		root: &tocSimNode{},
+	}
+	p.load(tree)
+	for node := range tree.All() {
+		p.plan.existing = append(p.plan.existing, node.UUID)
+	}
+	p.match(tree, desired)
+	p.place(nil, p.root, desired)
+	p.edit(desired)
+	p.remove(p.root)
+
+	return p.plan, nil
+}
+
+func validateDesiredTOC(desired []*DesiredTOCNode) error {
+	var errs []error
+	docIDs := make(map[int]bool)
+	uuids := make(map[string]bool)
+
+	var walk func(nodes []*DesiredTOCNode)
+	walk = func(nodes []*DesiredTOCNode) {
+		for _, n := range nodes {
+			if n == nil {
+				errs = append(errs, errors.New("desired toc: nil node"))
+				continue
+			}
+
+			switch n.nodeType() {
+			case TOCTypeDoc:
+				if n.DocID <= 0 {
+					errs = append(errs, fmt.Errorf("desired toc: %s requires doc_id", n))
+				} else if docIDs[n.DocID] {
+					errs = append(errs, fmt.Errorf("desired toc: duplicate doc_id %d", n.DocID))
+				}
+				docIDs[n.DocID] = true
+			case TOCTypeLink:
+				if n.URL == "" {
+					errs = append(errs, fmt.Errorf("desired toc: %s requires url", n))
+				}
+			case TOCTypeTitle:
+				if n.Title == "" {
+					errs = append(errs, errors.New("desired toc: TITLE node requires title"))
+				}
+			default:
+				errs = append(errs, fmt.Errorf("desired toc: unknown node type %q", n.Type))
+			}
+
+			if n.UUID != "" {
+				if uuids[n.UUID] {
+					errs = append(errs, fmt.Errorf("desired toc: duplicate uuid %q", n.UUID))
+				}
+				uuids[n.UUID] = true
+			}
+
+			walk(n.Children)
+		}
+	}
+	walk(desired)
+
+	return errors.Join(errs...)
+}
+
+// tocSimNode 规划过程中模拟的目录节点
+type tocSimNode struct {
+	toc      *TOC
+	desired  *DesiredTOCNode
+	parent   *tocSimNode
+	children []*tocSimNode
+}
+
+func (n *tocSimNode) detach() {
+	if n.parent == nil {
+		return
+	}
+	n.parent.children = slices.DeleteFunc(n.parent.children, func(c *tocSimNode) bool { return c == n })
+	n.parent = nil
+}
+
+// attach 将节点挂到 parent 下 after 之后, after 为 nil 时作为第一个子节点
+func (n *tocSimNode) attach(parent, after *tocSimNode) {
+	i := 0
+	if after != nil {
+		i = slices.Index(parent.children, after) + 1
+	}
+	parent.children = slices.Insert(parent.children, i, n)
+	n.parent = parent
+}
+
+type tocPlanner struct {
+	plan    *TOCPlan
+	sims    map[*DesiredTOCNode]*tocSimNode
+	root    *tocSimNode
+	current map[string]*tocSimNode
+}
+
+func (p *tocPlanner) load(tree *TOCTree) {
+	p.current = make(map[string]*tocSimNode, tree.Len())
+
+	var build func(parent *tocSimNode, nodes []*TOCNode)
+	build = func(parent *tocSimNode, nodes []*TOCNode) {
+		for _, node := range nodes {
+			sim := &tocSimNode{toc: node.TOC, parent: parent}
+			parent.children = append(parent.children, sim)
+			p.current[node.UUID] = sim
+			build(sim, node.Children)
+		}
+	}
+	build(p.root, tree.Roots())
+}
+
+func (p *tocPlanner) match(tree *TOCTree, desired []*DesiredTOCNode) {
+	// bind 绑定期望节点与现有节点, 仅可绑定目录树中可达且未被使用的节点
+	bind := func(d *DesiredTOCNode, uuid string) bool {
+		sim, ok := p.current[uuid]
+		if !ok || sim.desired != nil {
+			return false
+		}
+		p.plan.matched[d] = uuid
+		sim.desired = d
+		p.sims[d] = sim
+		return true
+	}
+
+	byPath := make(map[string][]string)
+	for node := range tree.All() {
+		if node.Type != TOCTypeDoc {
+			key := string(node.Type) + ":" + node.Path()
+			byPath[key] = append(byPath[key], node.UUID)
+		}
+	}
+
+	var walk func(nodes []*DesiredTOCNode, prefix string, visit func(d *DesiredTOCNode, path string))
+	walk = func(nodes []*DesiredTOCNode, prefix string, visit func(d *DesiredTOCNode, path string)) {
+		for _, d := range nodes {
+			path := d.Title
+			if prefix != "" {
+				path = prefix + TOCPathSeparator + d.Title
+			}
+			visit(d, path)
+			walk(d.Children, path, visit)
+		}
+	}
+
+	// explicit uuid first, so that it wins over the other rules
+	walk(desired, "", func(d *DesiredTOCNode, _ string) {
+		if d.UUID != "" {
+			bind(d, d.UUID)
+		}
+	})
+
+	// then doc id, or type and title path
+	walk(desired, "", func(d *DesiredTOCNode, path string) {
+		if _, ok := p.sims[d]; ok {
+			return
+		}
+
+		if d.nodeType() == TOCTypeDoc {
+			if node, ok := tree.NodeByDocID(d.DocID); ok {
+				bind(d, node.UUID)
+			}
+			return
+		}
+
+		for _, uuid := range byPath[string(d.nodeType())+":"+path] {
+			if bind(d, uuid) {
+				return
+			}
+		}
+	})
+}
+
+// place 按深度优先顺序将期望节点插入/移动到目标位置
+func (p *tocPlanner) place(parent *DesiredTOCNode, parentSim *tocSimNode, children []*DesiredTOCNode) {
+	stable := p.stable(parentSim, children)
+
+	for k, d := range children {
+		var after *DesiredTOCNode
+		var afterSim *tocSimNode
+		if k > 0 {
+			after = children[k-1]
+			afterSim = p.sims[after]
+		}
+
+		sim, ok := p.sims[d]
+		switch {
+		case !ok:
+			sim = &tocSimNode{desired: d}
+			p.sims[d] = sim
+			sim.attach(parentSim, afterSim)
+			p.plan.Operations = append(p.plan.Operations, &TOCOperation{
+				Kind:   TOCOperationInsert,
+				Node:   d,
+				Parent: parent,
+				After:  after,
+			})
+		case !stable[k]:
+			sim.detach()
+			sim.attach(parentSim, afterSim)
+			p.plan.Operations = append(p.plan.Operations, &TOCOperation{
+				Kind:    TOCOperationMove,
+				Node:    d,
+				Current: sim.toc,
+				Parent:  parent,
+				After:   after,
+			})
+		}
+
+		p.place(d, sim, d.Children)
+	}
+}
+
+// stable 返回无需移动的期望子节点下标:
+// 已在 parentSim 下且当前顺序构成最长递增子序列的节点
+func (p *tocPlanner) stable(parentSim *tocSimNode, children []*DesiredTOCNode) map[int]bool {
+	var ks, positions []int
+	for k, d := range children {
+		sim, ok := p.sims[d]
+		if !ok || sim.parent != parentSim {
+			continue
+		}
+		ks = append(ks, k)
+		positions = append(positions, slices.Index(parentSim.children, sim))
+	}
+
+	stable := make(map[int]bool, len(ks))
+	for _, i := range longestIncreasingSubsequence(positions) {
+		stable[ks[i]] = true
+	}
+	return stable
+}
+
+// longestIncreasingSubsequence 返回最长严格递增子序列的下标
+func longestIncreasingSubsequence(values []int) []int {
+	var tails []int // tails[l] 为长度 l+1 的递增子序列结尾下标
+	prev := make([]int, len(values))
+
+	for i, v := range values {
+		l := sort.Search(len(tails), func(j int) bool { return values[tails[j]] >= v })
+		if l > 0 {
+			prev[i] = tails[l-1]
+		} else {
+			prev[i] = -1
+		}
+		if l == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[l] = i
+		}
+	}
+
+	result := make([]int, len(tails))
+	if len(tails) == 0 {
+		return result
+	}
+	for i, k := len(tails)-1, tails[len(tails)-1]; i >= 0; i-- {
+		result[i] = k
+		k = prev[k]
+	}
+	return result
+}
+
+// edit 对已匹配但属性不一致的节点生成编辑操作
+func (p *tocPlanner) edit(desired []*DesiredTOCNode) {
+	for _, d := range desired {
+		if sim := p.sims[d]; sim.toc != nil && tocNeedsEdit(sim.toc, d) {
+			p.plan.Operations = append(p.plan.Operations, &TOCOperation{
+				Kind:    TOCOperationEdit,
+				Node:    d,
+				Current: sim.toc,
+			})
+		}
+		p.edit(d.Children)
+	}
+}
+
+func tocNeedsEdit(toc *TOC, d *DesiredTOCNode) bool {
+	switch {
+	case d.Title != "" && d.Title != toc.Title:
+		return true
+	case d.nodeType() == TOCTypeLink && d.URL != toc.URL:
+		return true
+	case d.OpenWindow != nil && *d.OpenWindow != toc.OpenWindow:
+		return true
+	case d.Visible != nil && *d.Visible != toc.Visible:
+		return true
+	}
+	return false
+}
+
+// remove 删除未匹配的现有节点, 仅针对最上层的节点生成操作, 其子节点随之删除
+func (p *tocPlanner) remove(parent *tocSimNode) {
+	for _, sim := range parent.children {
+		if sim.desired == nil {
+			p.plan.Operations = append(p.plan.Operations, &TOCOperation{
+				Kind:    TOCOperationRemove,
+				Current: sim.toc,
+			})
+			continue
+		}
+		p.remove(sim)
+	}
+}
+
+// ApplyTOCPlan 按顺序执行目录变更计划, 返回最终的目录
+//
+// 新插入节点的 UUID 从每次 UpdateTOC 的返回结果中解析, 供后续操作引用
+func (s *docService) ApplyTOCPlan(ctx context.Context, bookID any, plan *TOCPlan, opts ...RequestOption) ([]*TOC, *Response, error) {
+	uuids := make(map[*DesiredTOCNode]string, len(plan.matched))
+	for d, uuid := range plan.matched {
+		uuids[d] = uuid
+	}
+
+	var (
+		tocs []*TOC
+		resp *Response
+	)
+	for i, op := range plan.Operations {
+		request, err := op.request(uuids)
+		if err != nil {
+			return tocs, resp, fmt.Errorf("toc operation %d (%s): %w", i+1, op, err)
+		}
+
+		known := make(map[string]bool, len(plan.existing)+len(tocs))
+		for _, uuid := range plan.existing {
+			known[uuid] = true
+		}
+		for _, toc := range tocs {
+			known[toc.UUID] = true
+		}
+
+		tocs, resp, err = s.UpdateTOC(ctx, bookID, request, opts...)
+		if err != nil {
+			return nil, resp, fmt.Errorf("toc operation %d (%s): %w", i+1, op, err)
+		}
+
+		if op.Kind == TOCOperationInsert {
+			uuid, ok := findInsertedTOC(tocs, known, op.Node)
+			if !ok {
+				return tocs, resp, fmt.Errorf("toc operation %d (%s): inserted node not found", i+1, op)
+			}
+			uuids[op.Node] = uuid
+		}
+	}
+
+	return tocs, resp, nil
+}
+
+func (op *TOCOperation) request(uuids map[*DesiredTOCNode]string) (*UpdateTOCRequest, error) {
+	resolve := func(d *DesiredTOCNode) (string, error) {
+		uuid, ok := uuids[d]
+		if !ok {
+			return "", fmt.Errorf("unresolved node %s", d)
+		}
+		return uuid, nil
+	}
+
+	request := &UpdateTOCRequest{}
+	switch op.Kind {
+	case TOCOperationInsert, TOCOperationMove:
+		if op.After != nil {
+			target, err := resolve(op.After)
+			if err != nil {
+				return nil, err
+			}
+			request.Action = new(TOCActionAppendNode)
+			request.ActionMode = new(TOCActionModeSibling)
+			request.TargetUUID = new(target)
+		} else {
+			request.Action = new(TOCActionPrependNode)
+			request.ActionMode = new(TOCActionModeChild)
+			if op.Parent != nil {
+				target, err := resolve(op.Parent)
+				if err != nil {
+					return nil, err
+				}
+				request.TargetUUID = new(target)
+			}
+		}
+
+		if op.Kind == TOCOperationMove {
+			request.NodeUUID = new(op.Current.UUID)
+			return request, nil
+		}
+
+		request.Type = new(op.Node.nodeType())
+		if op.Node.nodeType() == TOCTypeDoc {
+			request.DocIDs = []int{op.Node.DocID}
+		}
+		if op.Node.Title != "" {
+			request.Title = new(op.Node.Title)
+		}
+		if op.Node.URL != "" {
+			request.URL = new(op.Node.URL)
+		}
+		request.OpenWindow = op.Node.OpenWindow
+		request.Visible = op.Node.Visible
+	case TOCOperationEdit:
+		request.Action = new(TOCActionEditNode)
+		request.NodeUUID = new(op.Current.UUID)
+		if op.Node.Title != "" {
+			request.Title = new(op.Node.Title)
+		}
+		if op.Node.URL != "" {
+			request.URL = new(op.Node.URL)
+		}
+		request.OpenWindow = op.Node.OpenWindow
+		request.Visible = op.Node.Visible
+	case TOCOperationRemove:
+		request.Action = new(TOCActionRemoveNode)
+		request.ActionMode = new(TOCActionModeChild)
+		request.NodeUUID = new(op.Current.UUID)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Kind)
+	}
+
+	return request, nil
+}
+
+// findInsertedTOC 在更新后的目录中查找新插入的节点
+func findInsertedTOC(tocs []*TOC, known map[string]bool, d *DesiredTOCNode) (string, bool) {
+	for _, toc := range tocs {
+		if known[toc.UUID] || toc.Type != d.nodeType() {
+			continue
+		}
+		if d.nodeType() == TOCTypeDoc && toc.docID() != d.DocID {
+			continue
+		}
+		if d.nodeType() != TOCTypeDoc && toc.Title != d.Title {
+			continue
+		}
+		return toc.UUID, true
+	}
+	return "", false
+}
+
+type ReconcileTOCRequest struct {
+	Desired []*DesiredTOCNode // 期望的目录
+	DryRun  bool              // 仅计算变更计划, 不执行
+	Output  io.Writer         // 执行前输出变更计划 [可选]
+}
+
+// ReconcileTOC 将知识库目录调整为期望的目录
+//
+// bookID: 知识库 ID 或 命名空间(group_login/book_slug)
+//
+// 返回计算出的变更计划; 非 dry-run 模式下会依次执行计划中的变更
+func (s *docService) ReconcileTOC(ctx context.Context, bookID any, request *ReconcileTOCRequest, opts ...RequestOption) (*TOCPlan, *Response, error) {
+	tocs, resp, err := s.GetTOCs(ctx, bookID, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	plan, err := PlanTOC(tocs, request.Desired)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	if request.Output != nil {
+		if _, err := plan.WriteTo(request.Output); err != nil {
+			return plan, resp, err
+		}
+	}
+
+	if request.DryRun || plan.Empty() {
+		return plan, resp, nil
+	}
+
+	_, resp, err = s.ApplyTOCPlan(ctx, bookID, plan, opts...)
+	return plan, resp, err
+}
@@ -0,0 +1,245 @@
+package yuque
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDesiredTOC 返回与 newTestTOCs 相同的期望目录
+func newTestDesiredTOC() []*DesiredTOCNode {
+	return []*DesiredTOCNode{
+		{Title: "设计", Children: []*DesiredTOCNode{
+			{Title: "后端", Children: []*DesiredTOCNode{
+				{DocID: 103},
+				{DocID: 104},
+			}},
+			{Title: "前端", URL: "https://example.com"},
+		}},
+		{DocID: 105},
+	}
+}
+
+// newTestChangedTOC 返回如下期望目录:
+//
+//	规范
+//	设计
+//	├── 后端
+//	│   ├── 队列
+//	│   └── 缓存设计
+//	└── 运维
+//	    └── #106
+func newTestChangedTOC() []*DesiredTOCNode {
+	return []*DesiredTOCNode{
+		{DocID: 105},
+		{Title: "设计", Children: []*DesiredTOCNode{
+			{Title: "后端", Children: []*DesiredTOCNode{
+				{DocID: 104},
+				{DocID: 103, Title: "缓存设计"},
+			}},
+			{Title: "运维", Children: []*DesiredTOCNode{
+				{DocID: 106},
+			}},
+		}},
+	}
+}
+
+func TestPlanTOC_NoChanges(t *testing.T) {
+	plan, err := PlanTOC(newTestTOCs(), newTestDesiredTOC())
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+	assert.Empty(t, plan.String())
+}
+
+func TestPlanTOC_MinimalMoves(t *testing.T) {
+	// rotate the children of 后端 and the roots, each needs a single move
+	desired := newTestDesiredTOC()
+	desired[0], desired[1] = desired[1], desired[0]
+	backend := desired[1].Children[0]
+	backend.Children[0], backend.Children[1] = backend.Children[1], backend.Children[0]
+
+	plan, err := PlanTOC(newTestTOCs(), desired)
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 2)
+
+	assert.Equal(t, TOCOperationMove, plan.Operations[0].Kind)
+	assert.Equal(t, "u5", plan.Operations[0].Current.UUID)
+	assert.Nil(t, plan.Operations[0].Parent)
+	assert.Nil(t, plan.Operations[0].After)
+
+	assert.Equal(t, TOCOperationMove, plan.Operations[1].Kind)
+	assert.Equal(t, "u4", plan.Operations[1].Current.UUID)
+	assert.Same(t, backend, plan.Operations[1].Parent)
+	assert.Nil(t, plan.Operations[1].After)
+}
+
+func TestPlanTOC(t *testing.T) {
+	desired := newTestChangedTOC()
+
+	plan, err := PlanTOC(newTestTOCs(), desired)
+	require.NoError(t, err)
+
+	assert.Equal(t, `1. move DOC #105 [u5] first
+2. move DOC #104 [u4] first under TITLE "后端"
+3. insert TITLE "运维" after TITLE "后端" under TITLE "设计"
+4. insert DOC #106 first under TITLE "运维"
+5. edit DOC [u3] -> DOC "缓存设计" (#103)
+6. remove LINK "前端" [u6]
+`, plan.String())
+}
+
+func TestPlanTOC_InvalidDesired(t *testing.T) {
+	_, err := PlanTOC(newTestTOCs(), []*DesiredTOCNode{
+		{DocID: 103},
+		{Type: TOCTypeDoc, Title: "缺少文档"},
+		{Title: "分组", Children: []*DesiredTOCNode{{DocID: 103}}},
+		{Type: TOCTypeLink, Title: "缺少链接"},
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "duplicate doc_id 103")
+	assert.ErrorContains(t, err, "requires doc_id")
+	assert.ErrorContains(t, err, "requires url")
+}
+
+func TestPlanTOC_ExplicitUUID(t *testing.T) {
+	desired := newTestDesiredTOC()
+	// rename the group, binding it by uuid keeps it from being recreated
+	desired[0].Children[0].UUID = "u2"
+	desired[0].Children[0].Title = "服务端"
+
+	plan, err := PlanTOC(newTestTOCs(), desired)
+	require.NoError(t, err)
+	require.Len(t, plan.Operations, 1)
+	assert.Equal(t, TOCOperationEdit, plan.Operations[0].Kind)
+	assert.Equal(t, "u2", plan.Operations[0].Current.UUID)
+}
+
+func TestLongestIncreasingSubsequence(t *testing.T) {
+	assert.Empty(t, longestIncreasingSubsequence(nil))
+	assert.Equal(t, []int{0, 1, 2}, longestIncreasingSubsequence([]int{1, 2, 3}))
+	assert.Equal(t, []int{1, 2, 3}, longestIncreasingSubsequence([]int{3, 0, 1, 2}))
+	assert.Equal(t, []int{1}, longestIncreasingSubsequence([]int{1, 0}))
+}
+
+// newTestTOCServer 返回一个记录 UpdateTOC 请求的服务, 插入节点时为其分配新的 UUID
+func newTestTOCServer(t *testing.T) (*Client, func() []*UpdateTOCRequest) {
+	var (
+		mu       sync.Mutex
+		requests []*UpdateTOCRequest
+		tocs     = newTestTOCs()
+	)
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/org/book/toc", r.URL.Path)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPut {
+			var req UpdateTOCRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			requests = append(requests, &req)
+
+			if *req.Action != TOCActionRemoveNode && *req.Action != TOCActionEditNode && req.NodeUUID == nil {
+				toc := &TOC{UUID: fmt.Sprintf("n%d", len(requests)), Type: *req.Type}
+				if req.Title != nil {
+					toc.Title = *req.Title
+				}
+				if len(req.DocIDs) > 0 {
+					toc.DocID = req.DocIDs[0]
+				}
+				tocs = append(tocs, toc)
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": tocs})
+	}))
+
+	return client, func() []*UpdateTOCRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestDocService_ReconcileTOC(t *testing.T) {
+	client, requests := newTestTOCServer(t)
+
+	var out bytes.Buffer
+	plan, _, err := client.DocService.ReconcileTOC(ctx, "org/book", &ReconcileTOCRequest{
+		Desired: newTestChangedTOC(),
+		Output:  &out,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, plan.String(), out.String())
+
+	reqs := requests()
+	require.Len(t, reqs, 6)
+
+	// move 规范 to the top
+	assert.Equal(t, &UpdateTOCRequest{
+		Action:     new(TOCActionPrependNode),
+		ActionMode: new(TOCActionModeChild),
+		NodeUUID:   new("u5"),
+	}, reqs[0])
+
+	// move 队列 to the top of 后端
+	assert.Equal(t, &UpdateTOCRequest{
+		Action:     new(TOCActionPrependNode),
+		ActionMode: new(TOCActionModeChild),
+		TargetUUID: new("u2"),
+		NodeUUID:   new("u4"),
+	}, reqs[1])
+
+	// insert 运维 after 后端
+	assert.Equal(t, &UpdateTOCRequest{
+		Action:     new(TOCActionAppendNode),
+		ActionMode: new(TOCActionModeSibling),
+		TargetUUID: new("u2"),
+		Type:       new(TOCTypeTitle),
+		Title:      new("运维"),
+	}, reqs[2])
+
+	// insert doc 106 into the newly created 运维
+	assert.Equal(t, &UpdateTOCRequest{
+		Action:     new(TOCActionPrependNode),
+		ActionMode: new(TOCActionModeChild),
+		TargetUUID: new("n3"),
+		DocIDs:     []int{106},
+		Type:       new(TOCTypeDoc),
+	}, reqs[3])
+
+	assert.Equal(t, &UpdateTOCRequest{
+		Action:   new(TOCActionEditNode),
+		NodeUUID: new("u3"),
+		Title:    new("缓存设计"),
+	}, reqs[4])
+
+	assert.Equal(t, &UpdateTOCRequest{
+		Action:     new(TOCActionRemoveNode),
+		ActionMode: new(TOCActionModeChild),
+		NodeUUID:   new("u6"),
+	}, reqs[5])
+}
+
+func TestDocService_ReconcileTOC_DryRun(t *testing.T) {
+	client, requests := newTestTOCServer(t)
+
+	var out bytes.Buffer
+	plan, _, err := client.DocService.ReconcileTOC(ctx, "org/book", &ReconcileTOCRequest{
+		Desired: newTestChangedTOC(),
+		DryRun:  true,
+		Output:  &out,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, plan.Operations, 6)
+	assert.Contains(t, out.String(), `6. remove LINK "前端" [u6]`)
+	assert.Empty(t, requests())
+}
@@ -0,0 +1,337 @@
+package yuque
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// TOCPathSeparator 目录标题路径分隔符，如 "设计/后端/缓存"
+const TOCPathSeparator = "/"
+
+// TOCTree 由 GetTOCs 返回的扁平目录构建的目录树
+//
+// 同级节点按 PrevUUID/SiblingUUID 链排序，链断开时剩余节点按原始顺序追加在后
+type TOCTree struct {
+	roots   []*TOCNode
+	nodes   []*TOCNode
+	byUUID  map[string]*TOCNode
+	byDocID map[int]*TOCNode
+}
+
+// TOCNode 目录树节点
+type TOCNode struct {
+	*TOC
+
+	Parent   *TOCNode   // 父节点, 根节点为 nil
+	Children []*TOCNode // 子节点, 按同级顺序排列
+}
+
+// NewTOCTree 根据扁平目录构建目录树
+//
+// 父节点不存在的节点会被当作根节点处理; 父节点成环的节点不在树中,
+// 可通过 Node 查找但没有子节点. 可通过 Validate 检查目录完整性
+func NewTOCTree(tocs []*TOC) *TOCTree {
+	t := &TOCTree{
+		nodes:   make([]*TOCNode, 0, len(tocs)),
+		byUUID:  make(map[string]*TOCNode, len(tocs)),
+		byDocID: make(map[int]*TOCNode),
+	}
+
+	for _, toc := range tocs {
+		if toc == nil {
+			continue
+		}
+		if _, ok := t.byUUID[toc.UUID]; ok {
+			continue
+		}
+
+		node := &TOCNode{TOC: toc}
+		t.nodes = append(t.nodes, node)
+		t.byUUID[toc.UUID] = node
+		if docID := toc.docID(); docID > 0 {
+			if _, ok := t.byDocID[docID]; !ok {
+				t.byDocID[docID] = node
+			}
+		}
+	}
+
+	// group by parent, keeping the original order
+	groups := make(map[*TOCNode][]*TOCNode)
+	for _, node := range t.nodes {
+		parent := t.byUUID[node.ParentUUID]
+		if parent == node {
+			parent = nil
+		}
+		groups[parent] = append(groups[parent], node)
+	}
+
+	t.roots = orderTOCSiblings(groups[nil], "")
+	for _, node := range t.nodes {
+		children := groups[node]
+		if len(children) == 0 {
+			continue
+		}
+		node.Children = orderTOCSiblings(children, node.ChildUUID)
+	}
+
+	// link parents by walking from roots, so nodes in a parent cycle stay detached
+	linked := make(map[*TOCNode]bool, len(t.nodes))
+	var link func(parent *TOCNode, children []*TOCNode)
+	link = func(parent *TOCNode, children []*TOCNode) {
+		for _, child := range children {
+			linked[child] = true
+			child.Parent = parent
+			link(child, child.Children)
+		}
+	}
+	link(nil, t.roots)
+
+	// detached nodes lose their children, so that walking them terminates
+	for _, node := range t.nodes {
+		if !linked[node] {
+			node.Children = nil
+		}
+	}
+
+	return t
+}
+
+// orderTOCSiblings 按 PrevUUID/SiblingUUID 链对同级节点排序
+func orderTOCSiblings(siblings []*TOCNode, headUUID string) []*TOCNode {
+	inGroup := make(map[string]*TOCNode, len(siblings))
+	for _, node := range siblings {
+		inGroup[node.UUID] = node
+	}
+
+	head := inGroup[headUUID]
+	if head == nil {
+		for _, node := range siblings {
+			if _, ok := inGroup[node.PrevUUID]; !ok {
+				head = node
+				break
+			}
+		}
+	}
+
+	ordered := make([]*TOCNode, 0, len(siblings))
+	visited := make(map[*TOCNode]bool, len(siblings))
+	for node := head; node != nil && !visited[node]; node = inGroup[node.SiblingUUID] {
+		visited[node] = true
+		ordered = append(ordered, node)
+	}
+
+	for _, node := range siblings {
+		if !visited[node] {
+			visited[node] = true
+			ordered = append(ordered, node)
+		}
+	}
+
+	return ordered
+}
+
+// Roots 返回根节点
+func (t *TOCTree) Roots() []*TOCNode {
+	return t.roots
+}
+
+// Len 返回节点数量
+func (t *TOCTree) Len() int {
+	return len(t.nodes)
+}
+
+// All 按深度优先(前序)遍历所有节点
+func (t *TOCTree) All() iter.Seq[*TOCNode] {
+	return func(yield func(*TOCNode) bool) {
+		for _, root := range t.roots {
+			if !root.walk(yield) {
+				return
+			}
+		}
+	}
+}
+
+// Node 根据 UUID 查找节点
+func (t *TOCTree) Node(uuid string) (*TOCNode, bool) {
+	node, ok := t.byUUID[uuid]
+	return node, ok
+}
+
+// NodeByDocID 根据文档 ID 查找节点
+func (t *TOCTree) NodeByDocID(docID int) (*TOCNode, bool) {
+	node, ok := t.byDocID[docID]
+	return node, ok
+}
+
+// NodeByPath 根据标题路径查找节点, 如 "设计/后端/缓存"
+//
+// 路径按 "/" 拆分, 因此无法查找标题中包含 "/" 的节点, 此时使用 NodeByTitles;
+// 同级存在同名节点时返回第一个匹配的节点
+func (t *TOCTree) NodeByPath(path string) (*TOCNode, bool) {
+	return t.NodeByTitles(strings.Split(strings.Trim(path, TOCPathSeparator), TOCPathSeparator)...)
+}
+
+// NodeByTitles 根据逐级的标题查找节点, 如 ("设计", "后端", "缓存")
+//
+// 同级存在同名节点时返回第一个匹配的节点
+func (t *TOCTree) NodeByTitles(titles ...string) (*TOCNode, bool) {
+	if len(titles) == 0 || titles[0] == "" {
+		return nil, false
+	}
+
+	return findTOCNodeByPath(t.roots, titles)
+}
+
+func findTOCNodeByPath(nodes []*TOCNode, titles []string) (*TOCNode, bool) {
+	for _, node := range nodes {
+		if node.Title != titles[0] {
+			continue
+		}
+		if len(titles) == 1 {
+			return node, true
+		}
+		if found, ok := findTOCNodeByPath(node.Children, titles[1:]); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+// Validate 检查目录的链接关系, 返回所有发现的问题
+//
+// 返回的错误可以通过 errors.As 获取 *TOCTreeError
+func (t *TOCTree) Validate() error {
+	var errs []error
+	report := func(node *TOCNode, format string, args ...any) {
+		errs = append(errs, &TOCTreeError{UUID: node.UUID, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	reachable := make(map[*TOCNode]bool, len(t.nodes))
+	for node := range t.All() {
+		reachable[node] = true
+	}
+
+	for _, node := range t.nodes {
+		if node.ParentUUID != "" {
+			if _, ok := t.byUUID[node.ParentUUID]; !ok {
+				report(node, "parent %q not found", node.ParentUUID)
+			}
+		}
+
+		if !reachable[node] {
+			report(node, "node is unreachable from the roots")
+		}
+
+		if node.ChildUUID != "" {
+			child, ok := t.byUUID[node.ChildUUID]
+			switch {
+			case !ok:
+				report(node, "child %q not found", node.ChildUUID)
+			case child.ParentUUID != node.UUID:
+				report(node, "child %q has parent %q", node.ChildUUID, child.ParentUUID)
+			case child.PrevUUID != "":
+				report(node, "child %q is not the first sibling", node.ChildUUID)
+			}
+		}
+
+		if node.SiblingUUID != "" {
+			sibling, ok := t.byUUID[node.SiblingUUID]
+			switch {
+			case !ok:
+				report(node, "sibling %q not found", node.SiblingUUID)
+			case sibling.ParentUUID != node.ParentUUID:
+				report(node, "sibling %q has a different parent", node.SiblingUUID)
+			case sibling.PrevUUID != node.UUID:
+				report(node, "sibling %q points back to %q", node.SiblingUUID, sibling.PrevUUID)
+			}
+		}
+
+		if node.PrevUUID != "" {
+			prev, ok := t.byUUID[node.PrevUUID]
+			switch {
+			case !ok:
+				report(node, "prev %q not found", node.PrevUUID)
+			case prev.SiblingUUID != node.UUID:
+				report(node, "prev %q points forward to %q", node.PrevUUID, prev.SiblingUUID)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// All 按深度优先(前序)遍历当前节点及其所有子孙节点
+func (n *TOCNode) All() iter.Seq[*TOCNode] {
+	return func(yield func(*TOCNode) bool) {
+		n.walk(yield)
+	}
+}
+
+func (n *TOCNode) walk(yield func(*TOCNode) bool) bool {
+	if !yield(n) {
+		return false
+	}
+	for _, child := range n.Children {
+		if !child.walk(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ancestors 从父节点开始向上遍历所有祖先节点
+func (n *TOCNode) Ancestors() iter.Seq[*TOCNode] {
+	return func(yield func(*TOCNode) bool) {
+		for p := n.Parent; p != nil; p = p.Parent {
+			if !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+// TreeDepth 返回节点在树中的深度, 根节点为 0
+//
+// 与 TOC.Level 及 TOC.Depth 不同, TreeDepth 根据父节点计算, 不依赖接口返回的字段
+func (n *TOCNode) TreeDepth() int {
+	depth := 0
+	for range n.Ancestors() {
+		depth++
+	}
+	return depth
+}
+
+// Path 返回节点的标题路径, 如 "设计/后端/缓存"
+//
+// 标题中的 "/" 不做转义, 此类路径无法通过 NodeByPath 查找
+func (n *TOCNode) Path() string {
+	titles := []string{n.Title}
+	for p := range n.Ancestors() {
+		titles = append(titles, p.Title)
+	}
+
+	slices.Reverse(titles)
+
+	return strings.Join(titles, TOCPathSeparator)
+}
+
+// docID 返回节点的文档 ID, 兼容已弃用的 ID 字段
+func (t *TOC) docID() int {
+	if t.DocID > 0 {
+		return t.DocID
+	}
+	return t.ID
+}
+
+// TOCTreeError 目录链接关系错误
+type TOCTreeError struct {
+	UUID   string // 出错的节点 UUID
+	Reason string
+}
+
+func (e *TOCTreeError) Error() string {
+	return fmt.Sprintf("toc node %q: %s", e.UUID, e.Reason)
+}
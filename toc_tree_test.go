@@ -0,0 +1,171 @@
+package yuque
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTOCs 返回如下目录:
+//
+//	设计
+//	├── 后端
+//	│   ├── 缓存
+//	│   └── 队列
+//	└── 前端
+//	规范
+func newTestTOCs() []*TOC {
+	return []*TOC{
+		{UUID: "u5", Type: TOCTypeDoc, Title: "规范", DocID: 105, PrevUUID: "u1"},
+		{UUID: "u4", Type: TOCTypeDoc, Title: "队列", DocID: 104, ParentUUID: "u2", PrevUUID: "u3"},
+		{UUID: "u1", Type: TOCTypeTitle, Title: "设计", SiblingUUID: "u5", ChildUUID: "u2"},
+		{UUID: "u3", Type: TOCTypeDoc, Title: "缓存", DocID: 103, ParentUUID: "u2", SiblingUUID: "u4"},
+		{UUID: "u2", Type: TOCTypeTitle, Title: "后端", ParentUUID: "u1", SiblingUUID: "u6", ChildUUID: "u3"},
+		{UUID: "u6", Type: TOCTypeLink, Title: "前端", URL: "https://example.com", ParentUUID: "u1", PrevUUID: "u2"},
+	}
+}
+
+func collectTOCUUIDs(nodes iter.Seq[*TOCNode]) []string {
+	var uuids []string
+	for node := range nodes {
+		uuids = append(uuids, node.UUID)
+	}
+	return uuids
+}
+
+func TestTOCTree(t *testing.T) {
+	tree := NewTOCTree(newTestTOCs())
+	require.NoError(t, tree.Validate())
+
+	assert.Equal(t, 6, tree.Len())
+	require.Len(t, tree.Roots(), 2)
+	assert.Equal(t, "u1", tree.Roots()[0].UUID)
+	assert.Equal(t, "u5", tree.Roots()[1].UUID)
+
+	// depth-first walk
+	assert.Equal(t, []string{"u1", "u2", "u3", "u4", "u6", "u5"}, collectTOCUUIDs(tree.All()))
+
+	backend, ok := tree.Node("u2")
+	require.True(t, ok)
+	assert.Equal(t, []string{"u2", "u3", "u4"}, collectTOCUUIDs(backend.All()))
+	assert.Equal(t, "u1", backend.Parent.UUID)
+	assert.Equal(t, 1, backend.TreeDepth())
+
+	// early stop
+	var first []string
+	for node := range tree.All() {
+		first = append(first, node.UUID)
+		if len(first) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"u1", "u2"}, first)
+
+	// lookup by doc id
+	cache, ok := tree.NodeByDocID(103)
+	require.True(t, ok)
+	assert.Equal(t, "设计/后端/缓存", cache.Path())
+	assert.Equal(t, 2, cache.TreeDepth())
+	assert.Equal(t, []string{"u2", "u1"}, collectTOCUUIDs(cache.Ancestors()))
+
+	_, ok = tree.NodeByDocID(999)
+	assert.False(t, ok)
+
+	// lookup by path
+	node, ok := tree.NodeByPath("设计/后端/队列")
+	require.True(t, ok)
+	assert.Equal(t, "u4", node.UUID)
+
+	node, ok = tree.NodeByPath("/设计/前端/")
+	require.True(t, ok)
+	assert.Equal(t, "u6", node.UUID)
+
+	_, ok = tree.NodeByPath("设计/缓存")
+	assert.False(t, ok)
+	_, ok = tree.NodeByPath("")
+	assert.False(t, ok)
+
+	// titles containing the separator are only found by their segments
+	tree = NewTOCTree([]*TOC{
+		{UUID: "u1", Type: TOCTypeTitle, Title: "CI/CD", ChildUUID: "u2", Level: 0, Depth: 1},
+		{UUID: "u2", Type: TOCTypeDoc, Title: "发布", DocID: 101, ParentUUID: "u1", Level: 1, Depth: 2},
+	})
+	_, ok = tree.NodeByPath("CI/CD/发布")
+	assert.False(t, ok)
+	node, ok = tree.NodeByTitles("CI/CD", "发布")
+	require.True(t, ok)
+	assert.Equal(t, "u2", node.UUID)
+	assert.Equal(t, 1, node.TreeDepth())
+	assert.Equal(t, 1, node.Level)
+	assert.Equal(t, 2, node.Depth)
+	_, ok = tree.NodeByTitles()
+	assert.False(t, ok)
+}
+
+func TestTOCTree_Validate(t *testing.T) {
+	tocs := newTestTOCs()
+	tocs[1].PrevUUID = "u9"     // 队列 -> unknown prev
+	tocs[5].ParentUUID = "u404" // 前端 -> unknown parent
+
+	tree := NewTOCTree(tocs)
+	err := tree.Validate()
+	require.Error(t, err)
+
+	var treeErr *TOCTreeError
+	require.True(t, errors.As(err, &treeErr))
+
+	var uuids []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		require.True(t, errors.As(e, &treeErr))
+		uuids = append(uuids, treeErr.UUID)
+	}
+	slices.Sort(uuids)
+	uuids = slices.Compact(uuids)
+	assert.Equal(t, []string{"u2", "u3", "u4", "u6"}, uuids)
+
+	// broken chains still keep every node in the tree
+	assert.Len(t, collectTOCUUIDs(tree.All()), 6)
+	orphan, ok := tree.Node("u6")
+	require.True(t, ok)
+	assert.Nil(t, orphan.Parent)
+}
+
+func TestTOCTree_ParentCycle(t *testing.T) {
+	tocs := newTestTOCs()
+	tocs[2].ParentUUID = "u2" // 设计 -> 后端 -> 设计
+
+	tree := NewTOCTree(tocs)
+	assert.Equal(t, []string{"u5"}, collectTOCUUIDs(tree.All()))
+	assert.Error(t, tree.Validate())
+
+	// nodes of the cycle are detached and walk to themselves only
+	design, ok := tree.Node("u1")
+	require.True(t, ok)
+	assert.Nil(t, design.Parent)
+	assert.Equal(t, []string{"u1"}, collectTOCUUIDs(design.All()))
+}
+
+func TestTOCTree_FromGetTOCs(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(loadData(t, "internal/testdata/api/doc/get_tocs.json"))
+	}))
+
+	tocs, _, err := client.DocService.GetTOCs(ctx, "org/book")
+	require.NoError(t, err)
+
+	tree := NewTOCTree(tocs)
+	assert.Equal(t, []string{"5wNEEZX3KK_hwec1", "XmljcRk3475oflnH"}, collectTOCUUIDs(tree.All()))
+
+	node, ok := tree.NodeByDocID(163724494)
+	require.True(t, ok)
+	assert.Equal(t, "团队建设", node.Path())
+
+	// the fixture is a partial toc, so the child and trailing sibling are missing
+	assert.ErrorContains(t, tree.Validate(), `child "ZNUPE7oxf-FP_WbQ" not found`)
+	assert.ErrorContains(t, tree.Validate(), `sibling "Rj13VAHZwhw00nCn" not found`)
+}
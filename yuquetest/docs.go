@@ -0,0 +1,352 @@
+package yuquetest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// lookupDoc returns the doc of the repo by ID or slug.
+func (s *Server) lookupDoc(book *yuque.Book, idOrSlug string) *yuque.Doc {
+	if id, err := strconv.Atoi(idOrSlug); err == nil {
+		if doc, ok := s.docs[id]; ok && doc.BookID == book.ID {
+			return doc
+		}
+		return nil
+	}
+
+	for _, doc := range s.docs {
+		if doc.BookID == book.ID && doc.Slug == idOrSlug {
+			return doc
+		}
+	}
+	return nil
+}
+
+// docSlugTaken reports whether another doc of the repo uses the slug.
+func (s *Server) docSlugTaken(bookID int, slug string, exceptID int) bool {
+	for _, doc := range s.docs {
+		if doc.BookID == bookID && doc.Slug == slug && doc.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// docSummary returns the doc as listed by GetDocs, without its body.
+func docSummary(doc *yuque.Doc, optional []string) *yuque.Doc {
+	summary := &yuque.Doc{
+		ID:               doc.ID,
+		Type:             doc.Type,
+		Slug:             doc.Slug,
+		Title:            doc.Title,
+		Description:      doc.Description,
+		Cover:            doc.Cover,
+		UserID:           doc.UserID,
+		BookID:           doc.BookID,
+		LastEditorID:     doc.LastEditorID,
+		Public:           doc.Public,
+		Status:           doc.Status,
+		LikesCount:       doc.LikesCount,
+		ReadCount:        doc.ReadCount,
+		CommentsCount:    doc.CommentsCount,
+		WordCount:        doc.WordCount,
+		CreatedAt:        doc.CreatedAt,
+		UpdatedAt:        doc.UpdatedAt,
+		ContentUpdatedAt: doc.ContentUpdatedAt,
+		PublishedAt:      doc.PublishedAt,
+		FirstPublishedAt: doc.FirstPublishedAt,
+		User:             doc.User,
+		LastEditor:       doc.LastEditor,
+	}
+
+	for _, property := range optional {
+		switch strings.TrimSpace(property) {
+		case "hits":
+			summary.Hits = doc.Hits
+		case "tags":
+			summary.Tags = doc.Tags
+		case "latest_version_id":
+			summary.LatestVersionID = doc.LatestVersionID
+		}
+	}
+
+	return summary
+}
+
+// setDocBody sets the body of the doc in the given format: the body is kept
+// as is, and mirrored to body_html or body_lake for the html and lake formats.
+func setDocBody(doc *yuque.Doc, format yuque.DocFormat, body string) {
+	doc.Format = &format
+	doc.Body = &body
+	doc.BodyDraft = new("")
+	doc.BodyHTML, doc.BodyLake = nil, nil
+
+	switch format {
+	case yuque.DocFormatHTML:
+		doc.BodyHTML = &body
+	case yuque.DocFormatLake:
+		doc.BodyLake = &body
+	}
+
+	doc.WordCount = 0
+	for _, r := range body {
+		if !unicode.IsSpace(r) {
+			doc.WordCount++
+		}
+	}
+}
+
+func (s *Server) handleDocs(c *call, book *yuque.Book) {
+	switch c.r.Method {
+	case http.MethodGet:
+		docs := s.sortedDocs(book.ID)
+
+		optional := strings.Split(c.r.URL.Query().Get("optional_properties"), ",")
+		offset, limit := c.page(defaultLimit)
+
+		summaries := []*yuque.Doc{}
+		for _, doc := range paginate(docs, offset, limit) {
+			summaries = append(summaries, docSummary(doc, optional))
+		}
+		c.writeList(summaries, len(docs))
+
+	case http.MethodPost:
+		if !s.canWrite(book, c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		var req yuque.CreateDocRequest
+		if !c.decode(&req) {
+			return
+		}
+
+		now := s.now()
+		doc := &yuque.Doc{
+			ID:               s.nextID(),
+			Type:             yuque.DocTypeDoc,
+			Title:            "无标题",
+			UserID:           c.user.ID,
+			BookID:           book.ID,
+			LastEditorID:     c.user.ID,
+			Public:           book.Public,
+			Status:           1,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			ContentUpdatedAt: now,
+			PublishedAt:      now,
+			FirstPublishedAt: now,
+			User:             c.user,
+			LastEditor:       c.user,
+			Creator:          c.user,
+		}
+
+		doc.Slug = fmt.Sprintf("doc%d", doc.ID)
+		if req.Slug != nil && *req.Slug != "" {
+			if s.docSlugTaken(book.ID, *req.Slug, 0) {
+				c.invalid("slug", "already_exists", "slug already exists")
+				return
+			}
+			doc.Slug = *req.Slug
+		}
+		if req.Title != nil && *req.Title != "" {
+			doc.Title = *req.Title
+		}
+		if req.Public != nil {
+			doc.Public = *req.Public
+		}
+
+		format := yuque.DocFormatMarkdown
+		if req.Format != nil {
+			format = *req.Format
+		}
+		var body string
+		if req.Body != nil {
+			body = *req.Body
+		}
+		setDocBody(doc, format, body)
+
+		s.docs[doc.ID] = doc
+		s.addVersion(doc, c.user)
+		book.ItemsCount++
+		book.ContentUpdatedAt = now
+
+		c.write(s.docDetail(doc))
+
+	default:
+		c.methodNotAllowed()
+	}
+}
+
+// docDetail returns the doc as returned by GetDoc, with its repo.
+func (s *Server) docDetail(doc *yuque.Doc) *yuque.Doc {
+	detail := *doc
+	detail.Book = s.books[doc.BookID]
+	return &detail
+}
+
+func (s *Server) handleDoc(c *call, book *yuque.Book, idOrSlug string) {
+	doc := s.lookupDoc(book, idOrSlug)
+	if doc == nil {
+		c.notFound()
+		return
+	}
+
+	switch c.r.Method {
+	case http.MethodGet:
+		c.write(s.docDetail(doc))
+
+	case http.MethodPut:
+		if !s.canWrite(book, c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		var req yuque.UpdateDocRequest
+		if !c.decode(&req) {
+			return
+		}
+
+		if req.Slug != nil {
+			if *req.Slug == "" || s.docSlugTaken(book.ID, *req.Slug, doc.ID) {
+				c.invalid("slug", "already_exists", "slug already exists")
+				return
+			}
+			doc.Slug = *req.Slug
+		}
+		if req.Public != nil {
+			doc.Public = *req.Public
+		}
+
+		changed := false
+		if req.Title != nil && *req.Title != doc.Title {
+			doc.Title = *req.Title
+			changed = true
+		}
+
+		format, body := *doc.Format, *doc.Body
+		if req.Format != nil {
+			format = *req.Format
+		}
+		if req.Body != nil {
+			body = *req.Body
+		}
+		if format != *doc.Format || body != *doc.Body {
+			setDocBody(doc, format, body)
+			changed = true
+		}
+
+		now := s.now()
+		doc.UpdatedAt = now
+		doc.LastEditorID = c.user.ID
+		doc.LastEditor = c.user
+		if changed {
+			doc.ContentUpdatedAt = now
+			doc.PublishedAt = now
+			book.ContentUpdatedAt = now
+			s.addVersion(doc, c.user)
+		}
+
+		c.write(s.docDetail(doc))
+
+	case http.MethodDelete:
+		if !s.canWrite(book, c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		s.deleteDoc(doc.ID)
+		book.ItemsCount--
+
+		c.write(doc)
+
+	default:
+		c.methodNotAllowed()
+	}
+}
+
+// deleteDoc removes the doc along with its versions and TOC nodes.
+func (s *Server) deleteDoc(id int) {
+	doc := s.docs[id]
+	delete(s.docs, id)
+
+	s.versions = slices.DeleteFunc(s.versions, func(v *yuque.DocVersion) bool { return v.DocID == id })
+	if root, ok := s.tocs[doc.BookID]; ok {
+		root.removeDoc(id)
+	}
+}
+
+// addVersion publishes the current content of the doc as a new version.
+func (s *Server) addVersion(doc *yuque.Doc, user *yuque.User) {
+	version := &yuque.DocVersion{
+		ID:        s.nextID(),
+		DocID:     doc.ID,
+		Slug:      doc.Slug,
+		Title:     doc.Title,
+		UserID:    user.ID,
+		CreatedAt: doc.ContentUpdatedAt,
+		UpdatedAt: doc.ContentUpdatedAt,
+		User:      user,
+		Format:    doc.Format,
+		Body:      doc.Body,
+		BodyHTML:  doc.BodyHTML,
+	}
+	s.versions = append(s.versions, version)
+	doc.LatestVersionID = version.ID
+}
+
+// routeDocVersion dispatches doc_versions?doc_id= and doc_versions/{id} requests.
+func (s *Server) routeDocVersion(c *call, segs []string) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+
+	switch len(segs) {
+	case 0:
+		doc := s.docs[c.queryInt("doc_id", 0)]
+		if doc == nil {
+			c.notFound()
+			return
+		}
+		if !s.canRead(s.books[doc.BookID], c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		// newest first, without the content
+		versions := []*yuque.DocVersion{}
+		for _, v := range slices.Backward(s.versions) {
+			if v.DocID == doc.ID {
+				summary := *v
+				summary.Format, summary.Body, summary.BodyHTML = nil, nil, nil
+				versions = append(versions, &summary)
+			}
+		}
+		c.write(versions)
+
+	case 1:
+		id, _ := strconv.Atoi(segs[0])
+		i := slices.IndexFunc(s.versions, func(v *yuque.DocVersion) bool { return v.ID == id })
+		if i < 0 {
+			c.notFound()
+			return
+		}
+
+		version := s.versions[i]
+		if !s.canRead(s.books[s.docs[version.DocID].BookID], c.user.ID) {
+			c.forbidden()
+			return
+		}
+		c.write(version)
+
+	default:
+		c.notFound()
+	}
+}
@@ -0,0 +1,229 @@
+package yuquetest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault is a failure injected by a FaultTransport into the requests it matches.
+//
+// A fault injects its Latency first, then either resets the connection,
+// responds with StatusCode, or truncates the body of the real response.
+type Fault struct {
+	// Method restricts the fault to requests of the method, empty matches all methods.
+	Method string
+
+	// Path restricts the fault to requests whose URL path matches the path.Match
+	// pattern, such as "/repos/*/docs", empty matches all paths.
+	Path string
+
+	// Probability is the probability, from 0 to 1, of injecting the fault into
+	// a matched request, nil injects it into every matched request and a zero
+	// probability into none, such as Probability: new(0.3).
+	Probability *float64
+
+	// Times is the number of times the fault is injected, zero is unlimited.
+	Times int
+
+	// Latency delays the request, or fails it with the error of its context if done first.
+	Latency time.Duration
+
+	// Reset fails the request with a connection reset error, without sending it.
+	Reset bool
+
+	// StatusCode responds with an error envelope of the status, without sending the request.
+	StatusCode int
+
+	// RetryAfter sets the Retry-After header of the StatusCode response, in whole seconds.
+	RetryAfter time.Duration
+
+	// Truncate cuts the body of the real response to half its length, failing
+	// its read with io.ErrUnexpectedEOF.
+	Truncate bool
+
+	injected int
+}
+
+// LatencyFault returns a fault delaying every request by d.
+func LatencyFault(d time.Duration) *Fault {
+	return &Fault{Latency: d}
+}
+
+// RateLimitFault returns a fault responding 429 Too Many Requests with the Retry-After header.
+func RateLimitFault(retryAfter time.Duration) *Fault {
+	return &Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// ServerErrorFault returns a fault responding with the 5xx status to the next n requests.
+func ServerErrorFault(status, n int) *Fault {
+	return &Fault{StatusCode: status, Times: n}
+}
+
+// TruncateFault returns a fault truncating the body of every response.
+func TruncateFault() *Fault {
+	return &Fault{Truncate: true}
+}
+
+// ResetFault returns a fault resetting the connection of every request.
+func ResetFault() *Fault {
+	return &Fault{Reset: true}
+}
+
+// FaultOption configures a FaultTransport.
+type FaultOption func(*FaultTransport)
+
+// WithFaultSeed seeds the random source deciding probabilistic faults, the default is seed 1.
+func WithFaultSeed(seed uint64) FaultOption {
+	return func(t *FaultTransport) {
+		t.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// FaultTransport is an http.RoundTripper injecting faults into the requests
+// sent through the wrapped transport, for resilience testing.
+//
+// Faults are checked in order and at most one is injected per request. Wrap
+// it with yuque.NewRetryableHTTPClient to test retries:
+//
+//	transport := yuquetest.NewFaultTransport(srv.Client().Transport, []*yuquetest.Fault{
+//		yuquetest.ServerErrorFault(http.StatusBadGateway, 2),
+//	})
+//	httpClient := yuque.NewRetryableHTTPClient(yuque.WithRetryableHTTPClientTransport(transport))
+type FaultTransport struct {
+	transport http.RoundTripper
+	faults    []*Fault
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewFaultTransport returns a transport injecting the faults into the requests sent through transport.
+func NewFaultTransport(transport http.RoundTripper, faults []*Fault, opts ...FaultOption) *FaultTransport {
+	t := &FaultTransport{
+		transport: transport,
+		faults:    faults,
+		rand:      rand.New(rand.NewPCG(1, 1)),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Injected returns the number of times the fault has been injected.
+func (t *FaultTransport) Injected(fault *Fault) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return fault.injected
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := t.next(req)
+	if fault == nil {
+		return t.transport.RoundTrip(req)
+	}
+
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	if fault.Reset || fault.StatusCode != 0 {
+		if req.Body != nil {
+			req.Body.Close() //nolint:errcheck
+		}
+	}
+
+	switch {
+	case fault.Reset:
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	case fault.StatusCode != 0:
+		return faultResponse(req, fault), nil
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || !fault.Truncate {
+		return resp, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data[:len(data)/2]), errReader{io.ErrUnexpectedEOF}))
+
+	return resp, nil
+}
+
+// next returns the fault to inject into the request, if any.
+func (t *FaultTransport) next(req *http.Request) *Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, fault := range t.faults {
+		if fault.Method != "" && fault.Method != req.Method {
+			continue
+		}
+		if fault.Path != "" {
+			if ok, _ := path.Match(fault.Path, req.URL.Path); !ok {
+				continue
+			}
+		}
+		if fault.Times > 0 && fault.injected >= fault.Times {
+			continue
+		}
+		if fault.Probability != nil && t.rand.Float64() >= *fault.Probability {
+			continue
+		}
+
+		fault.injected++
+		return fault
+	}
+	return nil
+}
+
+// faultResponse returns the error response of the fault, in the envelope of the real API.
+func faultResponse(req *http.Request, fault *Fault) *http.Response {
+	body := fmt.Sprintf(`{"status":%d,"message":%q}`, fault.StatusCode, http.StatusText(fault.StatusCode))
+
+	header := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	if fault.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Seconds())))
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fault.StatusCode, http.StatusText(fault.StatusCode)),
+		StatusCode:    fault.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,168 @@
+package yuquetest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// newFaultClient returns a client of srv sending requests through the faults,
+// retrying up to retryMax times without sleeping, and the recorded backoff waits.
+func newFaultClient(t *testing.T, srv *Server, retryMax int, faults ...*Fault) (*yuque.Client, *FaultTransport, func() []time.Duration) {
+	transport := NewFaultTransport(srv.Client().Transport, faults)
+
+	var (
+		mu    sync.Mutex
+		waits []time.Duration
+	)
+	backoff := func(min, max time.Duration, attempt int, resp *http.Response) time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, retryablehttp.DefaultBackoff(min, max, attempt, resp))
+		return 0
+	}
+
+	client, err := srv.NewClient(Token, yuque.WithHTTPClient(yuque.NewRetryableHTTPClient(
+		yuque.WithRetryableHTTPClientTransport(transport),
+		yuque.WithRetryableHTTPClientRetryMax(retryMax),
+		yuque.WithRetryableHTTPClientBackoff(backoff),
+	)))
+	require.NoError(t, err)
+
+	return client, transport, func() []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return waits
+	}
+}
+
+func TestFaultTransport_RateLimit(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	fault := RateLimitFault(3 * time.Second)
+	fault.Times = 2
+	client, transport, waits := newFaultClient(t, srv, 4, fault)
+
+	_, _, err := client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, transport.Injected(fault))
+	assert.Equal(t, []time.Duration{3 * time.Second, 3 * time.Second}, waits())
+}
+
+func TestFaultTransport_ServerErrors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	fault := ServerErrorFault(http.StatusBadGateway, 3)
+	fault.Path = "/user"
+
+	client, _, _ := newFaultClient(t, srv, 2, fault)
+	_, _, err := client.UserService.GetUser(ctx)
+	assert.ErrorIs(t, err, yuque.ErrServerError)
+	assert.ErrorContains(t, err, "code: 502")
+
+	// other paths are unaffected
+	_, _, err = client.UserService.Hello(ctx)
+	require.NoError(t, err)
+
+	// the burst is over
+	_, _, err = client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+}
+
+func TestFaultTransport_Reset(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	fault := ResetFault()
+	fault.Method = http.MethodPost
+	fault.Times = 1
+
+	client, transport, _ := newFaultClient(t, srv, 1, fault)
+	newTestRepo(t, client, "guide")
+	assert.Equal(t, 1, transport.Injected(fault))
+
+	// without retries
+	client, err := srv.NewClient(Token, yuque.WithHTTPClient(&http.Client{
+		Transport: NewFaultTransport(srv.Client().Transport, []*Fault{ResetFault()}),
+	}))
+	require.NoError(t, err)
+
+	_, _, err = client.UserService.GetUser(ctx)
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+}
+
+func TestFaultTransport_Truncate(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client, _, _ := newFaultClient(t, srv, 2, &Fault{Path: "/user", Truncate: true})
+
+	_, _, err := client.UserService.GetUser(ctx)
+	assert.ErrorContains(t, err, "invalid JSON response body")
+}
+
+func TestFaultTransport_Latency(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client, _, _ := newFaultClient(t, srv, 0, LatencyFault(20*time.Millisecond))
+
+	start := time.Now()
+	_, _, err := client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	client, _, _ = newFaultClient(t, srv, 0, LatencyFault(time.Minute))
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = client.UserService.GetUser(timeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFaultTransport_Probability(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	injected := func(seed uint64) []bool {
+		fault := &Fault{StatusCode: http.StatusServiceUnavailable, Probability: new(0.3)}
+		transport := NewFaultTransport(srv.Client().Transport, []*Fault{fault}, WithFaultSeed(seed))
+
+		client, err := srv.NewClient(Token, yuque.WithHTTPClient(&http.Client{Transport: transport}))
+		require.NoError(t, err)
+
+		var got []bool
+		for range 100 {
+			_, _, err := client.UserService.Hello(ctx)
+			got = append(got, err != nil)
+		}
+		assert.InDelta(t, 30, transport.Injected(fault), 15)
+		return got
+	}
+
+	assert.Equal(t, injected(42), injected(42))
+	assert.NotEqual(t, injected(42), injected(7))
+
+	// a zero probability never injects
+	fault := &Fault{StatusCode: http.StatusServiceUnavailable, Probability: new(0.0)}
+	transport := NewFaultTransport(srv.Client().Transport, []*Fault{fault})
+	client, err := srv.NewClient(Token, yuque.WithHTTPClient(&http.Client{Transport: transport}))
+	require.NoError(t, err)
+	for range 10 {
+		_, _, err := client.UserService.Hello(ctx)
+		require.NoError(t, err)
+	}
+	assert.Zero(t, transport.Injected(fault))
+}
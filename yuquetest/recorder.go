@@ -0,0 +1,444 @@
+package yuquetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay serves recorded interactions without touching the network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends requests through the real transport and records the interactions.
+	ModeRecord
+)
+
+// ErrNoInteraction is returned by a Recorder in replay mode when no recorded interaction matches a request.
+var ErrNoInteraction = errors.New("yuquetest: no recorded interaction matches")
+
+// redacted replaces the values of redacted headers.
+const redacted = "REDACTED"
+
+// defaultRedactedHeaders are the headers redacted from recorded interactions.
+var defaultRedactedHeaders = []string{headerAuthToken, "Authorization", "Cookie", "Set-Cookie"}
+
+// Interaction is a recorded request and response pair, saved as a JSON file.
+//
+// JSON bodies are saved as nested JSON, in the same format as the fixtures
+// of the yuque package, other bodies are saved as text.
+type Interaction struct {
+	Request  *RecordedRequest  `json:"request"`
+	Response *RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded request.
+type RecordedRequest struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Header   http.Header     `json:"header,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"body_text,omitempty"`
+}
+
+// RecordedResponse is a recorded response.
+type RecordedResponse struct {
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodyText   string          `json:"body_text,omitempty"`
+}
+
+// Matcher reports whether a request matches a recorded request.
+type Matcher func(r *http.Request, recorded *RecordedRequest) bool
+
+// DefaultMatcher matches the method, path and query of a request.
+var DefaultMatcher = MatchAll(MatchMethod, MatchPath, MatchQuery)
+
+// MatchAll returns a matcher matching requests that match all matchers.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(r *http.Request, recorded *RecordedRequest) bool {
+		for _, match := range matchers {
+			if !match(r, recorded) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchMethod matches the method of a request.
+func MatchMethod(r *http.Request, recorded *RecordedRequest) bool {
+	return r.Method == recorded.Method
+}
+
+// MatchPath matches the path of a request, ignoring the scheme and host.
+func MatchPath(r *http.Request, recorded *RecordedRequest) bool {
+	u, err := url.Parse(recorded.URL)
+	return err == nil && u.EscapedPath() == r.URL.EscapedPath()
+}
+
+// MatchQuery matches the query parameters of a request, regardless of their order.
+func MatchQuery(r *http.Request, recorded *RecordedRequest) bool {
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return maps.EqualFunc(u.Query(), r.URL.Query(), slices.Equal)
+}
+
+// MatchBody matches the body of a request, comparing JSON bodies semantically.
+//
+// The body is read through r.GetBody, leaving the request untouched; requests
+// with a body but no GetBody never match.
+func MatchBody(r *http.Request, recorded *RecordedRequest) bool {
+	body, err := copyBody(r)
+	if err != nil {
+		return false
+	}
+
+	if len(recorded.Body) > 0 {
+		var want, got any
+		return json.Unmarshal(recorded.Body, &want) == nil &&
+			json.Unmarshal(body, &got) == nil &&
+			equalJSON(want, got)
+	}
+	return string(body) == recorded.BodyText
+}
+
+func equalJSON(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithRecorderTransport sets the transport used in record mode, the default is http.DefaultTransport.
+func WithRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithRecorderMatcher sets the matcher used in replay mode, the default is DefaultMatcher.
+func WithRecorderMatcher(matcher Matcher) RecorderOption {
+	return func(r *Recorder) {
+		r.matcher = matcher
+	}
+}
+
+// WithRecorderRedactHeaders redacts additional headers from recorded interactions.
+//
+// X-Auth-Token, Authorization, Cookie and Set-Cookie are always redacted.
+func WithRecorderRedactHeaders(headers ...string) RecorderOption {
+	return func(r *Recorder) {
+		r.redact = append(r.redact, headers...)
+	}
+}
+
+// Recorder is a cassette-style http.RoundTripper, recording interactions to
+// the JSON files of a directory, or replaying them offline.
+//
+//	recorder, err := yuquetest.NewRecorder("testdata/cassettes/repos", yuquetest.ModeReplay)
+//	client, err := yuque.NewClient(token, yuque.WithHTTPClient(recorder.HTTPClient()))
+//
+// In replay mode each recorded interaction is served at most once, in the
+// order of recording, to the first request it matches.
+type Recorder struct {
+	dir       string
+	mode      Mode
+	transport http.RoundTripper
+	matcher   Matcher
+	redact    []string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewRecorder returns a recorder of the cassette in dir.
+//
+// In record mode the existing interactions of the cassette are removed, in
+// replay mode they are loaded and an error is returned if there are none.
+func NewRecorder(dir string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		dir:       dir,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		matcher:   DefaultMatcher,
+		redact:    slices.Clone(defaultRedactedHeaders),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	switch mode {
+	case ModeRecord:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				return nil, err
+			}
+		}
+
+	case ModeReplay:
+		if len(files) == 0 {
+			return nil, fmt.Errorf("yuquetest: no recorded interactions in %s", dir)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+
+			var interaction Interaction
+			if err := json.Unmarshal(data, &interaction); err != nil {
+				return nil, fmt.Errorf("yuquetest: invalid interaction %s: %w", file, err)
+			}
+			r.interactions = append(r.interactions, &interaction)
+		}
+		r.used = make([]bool, len(r.interactions))
+
+	default:
+		return nil, fmt.Errorf("yuquetest: unknown recorder mode %d", mode)
+	}
+
+	return r, nil
+}
+
+// HTTPClient returns an HTTP client using the recorder as its transport, to be passed to yuque.WithHTTPClient.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Unused returns the recorded interactions that have not been replayed.
+func (r *Recorder) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []*Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || !r.matcher(withBody(req, body), interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+
+		recorded := interaction.Response
+		body := []byte(recorded.BodyText)
+		if len(recorded.Body) > 0 {
+			body = recorded.Body
+		}
+
+		header := recorded.Header.Clone()
+		if header == nil {
+			header = make(http.Header)
+		}
+		header.Del("Content-Length")
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.transport.RoundTrip(withBody(req, reqBody))
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: &RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redactHeader(req.Header),
+		},
+		Response: &RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyText = splitBody(reqBody)
+	interaction.Response.Body, interaction.Response.BodyText = splitBody(respBody)
+
+	if err := r.save(interaction); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// save writes the interaction to the next file of the cassette, such as 001_get_repos_org_book.json.
+func (r *Recorder) save(interaction *Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, interaction)
+	r.used = append(r.used, true)
+
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	u, _ := url.Parse(interaction.Request.URL)
+	name := fmt.Sprintf("%03d_%s_%s.json", len(r.interactions), strings.ToLower(interaction.Request.Method), fileSlug(u.Path))
+
+	return os.WriteFile(filepath.Join(r.dir, name), append(data, '\n'), 0o644) //nolint:gosec
+}
+
+func (r *Recorder) redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range r.redact {
+		if header.Get(name) != "" {
+			header.Set(name, redacted)
+		}
+	}
+	return header
+}
+
+// readBody reads and closes the body of the request, as RoundTrip must.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close() //nolint:errcheck
+
+	return io.ReadAll(req.Body)
+}
+
+// withBody returns a copy of the request reading the body, which a RoundTripper
+// passes on instead of modifying the request it was given.
+func withBody(req *http.Request, body []byte) *http.Request {
+	clone := req.Clone(req.Context())
+	if body == nil {
+		clone.Body, clone.GetBody = http.NoBody, nil
+		return clone
+	}
+
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	clone.Body, _ = clone.GetBody()
+	return clone
+}
+
+// copyBody returns a copy of the body of the request through GetBody.
+func copyBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("yuquetest: request body cannot be copied")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() //nolint:errcheck
+
+	return io.ReadAll(body)
+}
+
+// splitBody returns the body as indented JSON if valid, otherwise as text.
+func splitBody(body []byte) (json.RawMessage, string) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ""
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return nil, string(body)
+	}
+	return indented.Bytes(), ""
+}
+
+// fileSlug turns the path into a file name fragment.
+func fileSlug(path string) string {
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.Trim(path, "/"))
+
+	if len(slug) > 64 {
+		slug = slug[:64]
+	}
+	if slug == "" {
+		return "root"
+	}
+	return slug
+}
@@ -0,0 +1,248 @@
+package yuquetest
+
+import (
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+var record = flag.Bool("record", false, "record the cassettes of testdata against the fake server")
+
+// newRecorderClient returns a client of the cassette, recording against srv in record mode.
+func newRecorderClient(t *testing.T, srv *Server, dir string, mode Mode, opts ...RecorderOption) (*yuque.Client, *Recorder) {
+	if mode == ModeRecord {
+		opts = append(opts, WithRecorderTransport(srv.Client().Transport))
+	}
+	recorder, err := NewRecorder(dir, mode, opts...)
+	require.NoError(t, err)
+
+	client, err := yuque.NewClient(Token,
+		yuque.WithBaseURL(srv.URL),
+		yuque.WithHTTPClient(recorder.HTTPClient()),
+		yuque.WithRateLimiter(nil),
+	)
+	require.NoError(t, err)
+
+	return client, recorder
+}
+
+func TestRecorder(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+
+	// record
+	client, _ := newRecorderClient(t, srv, dir, ModeRecord)
+
+	book := newTestRepo(t, client, "guide")
+	doc, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{
+		Title: new("入门"),
+		Body:  new("# 入门"),
+	})
+	require.NoError(t, err)
+	_, _, err = client.DocService.GetDocs(ctx, book.ID, &yuque.GetDocsRequest{Limit: new(10)})
+	require.NoError(t, err)
+	_, _, err = client.DocService.GetDoc(ctx, book.ID, 404)
+	require.ErrorIs(t, err, yuque.ErrNotFound)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 4)
+	assert.Equal(t, "001_post_users_yuque_repos.json", filepath.Base(files[0]))
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), Token)
+	assert.Contains(t, string(data), `"X-Auth-Token": [
+        "REDACTED"
+      ]`)
+	assert.Contains(t, string(data), `"slug": "guide"`)
+
+	// replay, after the server has gone
+	srv.Close()
+	client, recorder := newRecorderClient(t, srv, dir, ModeReplay)
+
+	got, _, err := client.DocService.GetDocs(ctx, book.ID, &yuque.GetDocsRequest{Limit: new(10)})
+	require.NoError(t, err)
+	require.Len(t, got.Docs, 1)
+	assert.Equal(t, doc.ID, got.Docs[0].ID)
+
+	_, _, err = client.DocService.GetDoc(ctx, book.ID, 404)
+	assert.ErrorIs(t, err, yuque.ErrNotFound)
+
+	// each interaction is replayed once
+	_, _, err = client.DocService.GetDoc(ctx, book.ID, 404)
+	assert.ErrorIs(t, err, ErrNoInteraction)
+
+	_, _, err = client.DocService.GetDocs(ctx, book.ID, &yuque.GetDocsRequest{Limit: new(20)})
+	assert.ErrorIs(t, err, ErrNoInteraction)
+
+	assert.Len(t, recorder.Unused(), 2)
+}
+
+func TestRecorder_MatchBody(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+
+	client, _ := newRecorderClient(t, srv, dir, ModeRecord)
+	newTestRepo(t, client, "a")
+	newTestRepo(t, client, "b")
+	srv.Close()
+
+	// the default matcher ignores the body, and replays in the order of recording
+	client, _ = newRecorderClient(t, srv, dir, ModeReplay)
+	book := newTestRepo(t, client, "b")
+	assert.Equal(t, "a", book.Slug)
+
+	client, _ = newRecorderClient(t, srv, dir, ModeReplay,
+		WithRecorderMatcher(MatchAll(DefaultMatcher, MatchBody)))
+	book = newTestRepo(t, client, "b")
+	assert.Equal(t, "b", book.Slug)
+	book = newTestRepo(t, client, "a")
+	assert.Equal(t, "a", book.Slug)
+}
+
+// closeBody records whether it is closed.
+type closeBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRecorder_RoundTripRequest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+
+	for _, mode := range []Mode{ModeRecord, ModeReplay} {
+		_, recorder := newRecorderClient(t, srv, dir, mode, WithRecorderMatcher(MatchAll(DefaultMatcher, MatchBody)))
+
+		body := &closeBody{Reader: strings.NewReader(`{"slug": "guide", "name": "guide"}`)}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/users/yuque/repos", body)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerAuthToken, Token)
+
+		resp, err := recorder.RoundTrip(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+
+		// the request is left as given, and its body closed
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Same(t, req, resp.Request)
+		assert.Same(t, body, req.Body)
+		assert.True(t, body.closed)
+	}
+}
+
+func TestRecorder_RedactHeaders(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	dir := t.TempDir()
+
+	client, _ := newRecorderClient(t, srv, dir, ModeRecord, WithRecorderRedactHeaders(headerRequestID))
+	_, _, err := client.UserService.Hello(ctx)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "001_get_hello.json"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), redacted))
+}
+
+func TestRecorder_NonJSON(t *testing.T) {
+	dir := t.TempDir()
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Header:     http.Header{"Content-Type": {"text/html"}},
+			Body:       io.NopCloser(strings.NewReader("<html>502 Bad Gateway</html>")),
+			Request:    r,
+		}, nil
+	})
+
+	recorder, err := NewRecorder(dir, ModeRecord, WithRecorderTransport(transport))
+	require.NoError(t, err)
+	client, err := yuque.NewClient(Token, yuque.WithHTTPClient(recorder.HTTPClient()), yuque.WithRateLimiter(nil))
+	require.NoError(t, err)
+	_, _, err = client.UserService.Hello(ctx)
+	require.ErrorIs(t, err, yuque.ErrServerError)
+
+	recorder, err = NewRecorder(dir, ModeReplay)
+	require.NoError(t, err)
+	client, err = yuque.NewClient(Token, yuque.WithHTTPClient(recorder.HTTPClient()), yuque.WithRateLimiter(nil))
+	require.NoError(t, err)
+
+	_, _, err = client.UserService.Hello(ctx)
+	require.ErrorIs(t, err, yuque.ErrServerError)
+	assert.Contains(t, err.Error(), "502 Bad Gateway")
+}
+
+func TestNewRecorder_Empty(t *testing.T) {
+	_, err := NewRecorder(t.TempDir(), ModeReplay)
+	assert.Error(t, err)
+}
+
+// TestRecorder_Cassette replays the cassette of testdata, run with -record to re-record it.
+//
+// The cassette is recorded against the fake server, not the real API.
+func TestRecorder_Cassette(t *testing.T) {
+	dir := filepath.Join("testdata", "cassettes", "fakeserver_repo")
+
+	if *record {
+		srv := NewServer(WithNow(func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }))
+		defer srv.Close()
+
+		client, _ := newRecorderClient(t, srv, dir, ModeRecord)
+		book := newTestRepo(t, client, "guide")
+		_, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{
+			Slug:  new("intro"),
+			Title: new("入门"),
+			Body:  new("# 入门"),
+		})
+		require.NoError(t, err)
+	}
+
+	// the fake server serves the API at its root, the host is never dialed
+	recorder, err := NewRecorder(dir, ModeReplay)
+	require.NoError(t, err)
+	client, err := yuque.NewClient(Token,
+		yuque.WithBaseURL("https://yuquetest.invalid/"),
+		yuque.WithHTTPClient(recorder.HTTPClient()),
+		yuque.WithRateLimiter(nil),
+	)
+	require.NoError(t, err)
+
+	book := newTestRepo(t, client, "guide")
+	assert.Equal(t, "yuque/guide", book.Namespace)
+
+	doc, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{
+		Slug:  new("intro"),
+		Title: new("入门"),
+		Body:  new("# 入门"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "intro", doc.Slug)
+	assert.Equal(t, "# 入门", *doc.Body)
+	assert.Empty(t, recorder.Unused())
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,288 @@
+package yuquetest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// owner is the user or group owning a repo.
+type owner struct {
+	user  *yuque.User
+	group *yuque.Group
+}
+
+func (o owner) id() int {
+	if o.group != nil {
+		return o.group.ID
+	}
+	return o.user.ID
+}
+
+func (o owner) login() string {
+	if o.group != nil {
+		return o.group.Login
+	}
+	return o.user.Login
+}
+
+// asUser returns the owner in the shape of Book.User.
+func (o owner) asUser() *yuque.User {
+	if o.group != nil {
+		return &yuque.User{
+			ID:          o.group.ID,
+			Type:        o.group.Type,
+			Login:       o.group.Login,
+			Name:        o.group.Name,
+			AvatarURL:   o.group.AvatarURL,
+			Description: o.group.Description,
+			CreatedAt:   o.group.CreatedAt,
+			UpdatedAt:   o.group.UpdatedAt,
+		}
+	}
+	return o.user
+}
+
+// lookupOwner returns the owner of users/{login} or groups/{login}.
+func (s *Server) lookupOwner(kind, login string) (owner, bool) {
+	if kind == "groups" {
+		group := s.lookupGroup(login)
+		return owner{group: group}, group != nil
+	}
+	user := s.lookupUser(login)
+	return owner{user: user}, user != nil
+}
+
+// ownerOf returns the owner of the repo.
+func (s *Server) ownerOf(book *yuque.Book) owner {
+	if group, ok := s.groups[book.UserID]; ok {
+		return owner{group: group}
+	}
+	return owner{user: s.users[book.UserID]}
+}
+
+// canRead reports whether the user can read the repo: public repos can be
+// read by everyone, private repos by their owner and the members of the owning group.
+func (s *Server) canRead(book *yuque.Book, userID int) bool {
+	return book.Public != yuque.AccessTypePrivate || book.UserID == userID || s.member(book.UserID, userID) != nil
+}
+
+// canWrite reports whether the user can modify the repo.
+func (s *Server) canWrite(book *yuque.Book, userID int) bool {
+	return s.canWriteOwner(book.UserID, userID)
+}
+
+// canWriteOwner reports whether the user can create and modify repos of the owner:
+// the user itself, or a member of the group with a role other than read-only.
+func (s *Server) canWriteOwner(ownerID, userID int) bool {
+	if ownerID == userID {
+		return true
+	}
+	m := s.member(ownerID, userID)
+	return m != nil && m.Role != yuque.GroupMemberRoleReadOnly
+}
+
+// lookupBook resolves the repo of repos/{id}/... or repos/{login}/{slug}/...,
+// returning the remaining path segments.
+func (s *Server) lookupBook(segs []string) (*yuque.Book, []string) {
+	if len(segs) == 0 {
+		return nil, nil
+	}
+
+	if id, err := strconv.Atoi(segs[0]); err == nil {
+		return s.books[id], segs[1:]
+	}
+
+	if len(segs) < 2 {
+		return nil, nil
+	}
+
+	namespace := segs[0] + "/" + segs[1]
+	for _, book := range s.books {
+		if book.Namespace == namespace {
+			return book, segs[2:]
+		}
+	}
+	return nil, nil
+}
+
+// sortedBooks returns the repos matching match in the order they were created.
+func (s *Server) sortedBooks(match func(*yuque.Book) bool) []*yuque.Book {
+	var books []*yuque.Book
+	for _, book := range s.books {
+		if match(book) {
+			books = append(books, book)
+		}
+	}
+	slices.SortFunc(books, func(a, b *yuque.Book) int { return a.ID - b.ID })
+	return books
+}
+
+func (s *Server) routeRepo(c *call, segs []string) {
+	book, rest := s.lookupBook(segs)
+	if book == nil {
+		c.notFound()
+		return
+	}
+
+	if !s.canRead(book, c.user.ID) {
+		c.forbidden()
+		return
+	}
+
+	switch {
+	case len(rest) == 0:
+		s.handleRepo(c, book)
+	case len(rest) == 1 && rest[0] == "docs":
+		s.handleDocs(c, book)
+	case len(rest) == 2 && rest[0] == "docs":
+		s.handleDoc(c, book, rest[1])
+	case len(rest) == 1 && rest[0] == "toc":
+		s.handleTOC(c, book)
+	default:
+		c.notFound()
+	}
+}
+
+func (s *Server) handleOwnerRepos(c *call, kind, login string) {
+	o, ok := s.lookupOwner(kind, login)
+	if !ok {
+		c.notFound()
+		return
+	}
+
+	switch c.r.Method {
+	case http.MethodGet:
+		bookType := yuque.BookType(c.r.URL.Query().Get("type"))
+		books := s.sortedBooks(func(book *yuque.Book) bool {
+			return book.UserID == o.id() &&
+				(bookType == "" || book.Type == bookType) &&
+				s.canRead(book, c.user.ID)
+		})
+
+		offset, limit := c.page(defaultLimit)
+		c.writeList(paginate(books, offset, limit), len(books))
+
+	case http.MethodPost:
+		if !s.canWriteOwner(o.id(), c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		var req yuque.CreateRepoRequest
+		if !c.decode(&req) {
+			return
+		}
+		if req.Name == nil || *req.Name == "" {
+			c.invalid("name", "missing_field", "name is required")
+			return
+		}
+		if req.Slug == nil || *req.Slug == "" {
+			c.invalid("slug", "missing_field", "slug is required")
+			return
+		}
+		if s.slugTaken(o, *req.Slug, 0) {
+			c.invalid("slug", "already_exists", "slug already exists")
+			return
+		}
+
+		now := s.now()
+		book := &yuque.Book{
+			ID:               s.nextID(),
+			Type:             yuque.BookTypeBook,
+			Slug:             *req.Slug,
+			Name:             *req.Name,
+			UserID:           o.id(),
+			CreatorID:        c.user.ID,
+			ContentUpdatedAt: now,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			Namespace:        o.login() + "/" + *req.Slug,
+			User:             o.asUser(),
+		}
+		if req.Description != nil {
+			book.Description = *req.Description
+		}
+		if req.Public != nil {
+			book.Public = *req.Public
+		}
+
+		s.books[book.ID] = book
+		s.tocs[book.ID] = &tocNode{}
+		c.write(book)
+
+	default:
+		c.methodNotAllowed()
+	}
+}
+
+// slugTaken reports whether another repo of the owner uses the slug.
+func (s *Server) slugTaken(o owner, slug string, exceptID int) bool {
+	for _, book := range s.books {
+		if book.UserID == o.id() && book.Slug == slug && book.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleRepo(c *call, book *yuque.Book) {
+	switch c.r.Method {
+	case http.MethodGet:
+		c.write(book)
+
+	case http.MethodPut:
+		if !s.canWrite(book, c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		var req yuque.UpdateRepoRequest
+		if !c.decode(&req) {
+			return
+		}
+
+		o := s.ownerOf(book)
+		if req.Slug != nil {
+			if *req.Slug == "" || s.slugTaken(o, *req.Slug, book.ID) {
+				c.invalid("slug", "already_exists", "slug already exists")
+				return
+			}
+			book.Slug = *req.Slug
+			book.Namespace = o.login() + "/" + *req.Slug
+		}
+		if req.Name != nil {
+			book.Name = *req.Name
+		}
+		if req.Description != nil {
+			book.Description = *req.Description
+		}
+		if req.Public != nil {
+			book.Public = *req.Public
+		}
+		book.UpdatedAt = s.now()
+
+		c.write(book)
+
+	case http.MethodDelete:
+		if !s.canWrite(book, c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		for id, doc := range s.docs {
+			if doc.BookID == book.ID {
+				s.deleteDoc(id)
+			}
+		}
+		delete(s.books, book.ID)
+		delete(s.tocs, book.ID)
+
+		c.write(book)
+
+	default:
+		c.methodNotAllowed()
+	}
+}
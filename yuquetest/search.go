@@ -0,0 +1,121 @@
+package yuquetest
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// searchPageSize is the number of results of a search page.
+const searchPageSize = 20
+
+// searchResult is a search result as returned by the API, with its target.
+type searchResult struct {
+	ID      int              `json:"id"`
+	Type    yuque.SearchType `json:"type"`
+	Title   string           `json:"title"`
+	Summary string           `json:"summary"`
+	URL     string           `json:"url"`
+	Info    string           `json:"info"`
+	Target  any              `json:"target"`
+}
+
+// handleSearch searches the titles and bodies of docs, or the names and
+// descriptions of repos, that the user can read, case-insensitively.
+func (s *Server) handleSearch(c *call) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+
+	query := c.r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		c.invalid("q", "missing_field", "q is required")
+		return
+	}
+	match := regexp.MustCompile("(?i)" + regexp.QuoteMeta(q))
+	highlight := func(text string) string {
+		return match.ReplaceAllString(text, "<em>$0</em>")
+	}
+
+	scope := query.Get("scope")
+	books := s.sortedBooks(func(book *yuque.Book) bool {
+		return s.canRead(book, c.user.ID) &&
+			(scope == "" || book.Namespace == scope || s.ownerOf(book).login() == scope)
+	})
+
+	var results []*searchResult
+	switch yuque.SearchType(query.Get("type")) {
+	case yuque.SearchTypeRepo:
+		for _, book := range books {
+			if !match.MatchString(book.Name) && !match.MatchString(book.Description) {
+				continue
+			}
+			results = append(results, &searchResult{
+				ID:      book.ID,
+				Type:    yuque.SearchTypeRepo,
+				Title:   highlight(book.Name),
+				Summary: highlight(book.Description),
+				URL:     "/" + book.Namespace,
+				Info:    s.ownerOf(book).asUser().Name,
+				Target:  book,
+			})
+		}
+
+	default:
+		for _, book := range books {
+			for _, doc := range s.sortedDocs(book.ID) {
+				if !match.MatchString(doc.Title) && !match.MatchString(*doc.Body) {
+					continue
+				}
+
+				target := docSummary(doc, nil)
+				target.Book = book
+				results = append(results, &searchResult{
+					ID:      doc.ID,
+					Type:    yuque.SearchTypeDoc,
+					Title:   highlight(doc.Title),
+					Summary: highlight(searchSummary(*doc.Body, match)),
+					URL:     "/" + book.Namespace + "/" + doc.Slug,
+					Info:    s.ownerOf(book).asUser().Name + " / " + book.Name,
+					Target:  target,
+				})
+			}
+		}
+	}
+
+	offset := max(c.queryInt("offset", 0), 0)
+	if page := c.queryInt("page", 0); page > 0 {
+		offset = (page - 1) * searchPageSize
+	}
+
+	c.writeList(paginate(results, offset, searchPageSize), len(results))
+}
+
+// sortedDocs returns the docs of the repo in the order they were created.
+func (s *Server) sortedDocs(bookID int) []*yuque.Doc {
+	var docs []*yuque.Doc
+	for _, doc := range s.docs {
+		if doc.BookID == bookID {
+			docs = append(docs, doc)
+		}
+	}
+	slices.SortFunc(docs, func(a, b *yuque.Doc) int { return a.ID - b.ID })
+	return docs
+}
+
+// searchSummary returns up to 100 runes of the body, starting shortly before the first match.
+func searchSummary(body string, match *regexp.Regexp) string {
+	const before, length = 20, 100
+
+	runes := []rune(body)
+	start := 0
+	if loc := match.FindStringIndex(body); loc != nil {
+		start = max(len([]rune(body[:loc[0]]))-before, 0)
+	}
+	return string(runes[start:min(start+length, len(runes))])
+}
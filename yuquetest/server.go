@@ -0,0 +1,305 @@
+// Package yuquetest provides an in-memory fake of the Yuque API for tests.
+//
+// The fake server implements the endpoints called by the yuque package,
+// backed by a stateful store of users, groups, repos, docs, versions and TOCs,
+// so that create-then-read flows can be tested end to end:
+//
+//	srv := yuquetest.NewServer()
+//	defer srv.Close()
+//
+//	client, _ := srv.NewClient(yuquetest.Token)
+//	book, _, _ := client.RepoService.CreateUserRepo(ctx, yuquetest.Login, &yuque.CreateRepoRequest{...})
+//
+// Errors use the envelope of the real API, {"status": 404, "message": "Not Found"},
+// with field errors for validation failures. Doc bodies are stored as given
+// and never rendered, and the Markdown TOC of UpdateRepoRequest is ignored.
+//
+// For regression tests against the real API, Recorder records interactions
+// once and replays them offline.
+package yuquetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flc1125/go-yuque"
+)
+
+const (
+	// Token is the token of the default user.
+	Token = "yuquetest-token"
+
+	// Login is the login of the default user.
+	Login = "yuque"
+
+	headerAuthToken = "X-Auth-Token"
+	headerRequestID = "X-Request-Id"
+
+	// defaultLimit is the page size used when a list request specifies no limit.
+	defaultLimit = 100
+)
+
+// Server is a fake Yuque API server backed by an in-memory store.
+//
+// The embedded httptest.Server serves the API at its root, so clients use
+// Server.URL as their base URL, see NewClient.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	now       func() time.Time
+	lastID    int
+	requestID int
+	tokens    map[string]int
+	users     map[int]*yuque.User
+	groups    map[int]*yuque.Group
+	members   []*yuque.GroupMember
+	books     map[int]*yuque.Book
+	docs      map[int]*yuque.Doc
+	versions  []*yuque.DocVersion
+	tocs      map[int]*tocNode
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithNow sets the clock used for timestamps, the default is time.Now.
+func WithNow(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// NewServer starts a fake server with a default user, whose login is Login
+// and whose token is Token. The caller should call Close when finished.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		now:    time.Now,
+		tokens: make(map[string]int),
+		users:  make(map[int]*yuque.User),
+		groups: make(map[int]*yuque.Group),
+		books:  make(map[int]*yuque.Book),
+		docs:   make(map[int]*yuque.Doc),
+		tocs:   make(map[int]*tocNode),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.AddUser(Login, "Yuque", Token)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// NewClient returns a client talking to the server with the given token.
+//
+// Client side rate limiting is disabled, opts are applied after the defaults.
+func (s *Server) NewClient(token string, opts ...yuque.ClientOption) (*yuque.Client, error) {
+	return yuque.NewClient(token, append([]yuque.ClientOption{
+		yuque.WithBaseURL(s.URL),
+		yuque.WithHTTPClient(s.Client()),
+		yuque.WithRateLimiter(nil),
+	}, opts...)...)
+}
+
+// AddUser adds a user authenticated by token and returns a copy of it.
+func (s *Server) AddUser(login, name, token string) *yuque.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	user := &yuque.User{
+		ID:        s.nextID(),
+		Type:      "User",
+		Login:     login,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.users[user.ID] = user
+	s.tokens[token] = user.ID
+
+	return new(*user)
+}
+
+// AddGroup adds a group administered by the given user and returns a copy of it.
+func (s *Server) AddGroup(login, name string, adminID int) *yuque.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	group := &yuque.Group{
+		ID:        s.nextID(),
+		Type:      "Group",
+		Login:     login,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.groups[group.ID] = group
+	s.setMember(group, adminID, yuque.GroupMemberRoleAdmin)
+
+	return new(*group)
+}
+
+// AddGroupMember adds the user to the group, or changes the role of an existing member.
+func (s *Server) AddGroupMember(groupID, userID int, role yuque.GroupMemberRole) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if group, ok := s.groups[groupID]; ok {
+		s.setMember(group, userID, role)
+	}
+}
+
+func (s *Server) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+// serveHTTP authenticates the request and dispatches it to the handler of its path.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requestID++
+	w.Header().Set(headerRequestID, strconv.Itoa(s.requestID))
+
+	userID, ok := s.tokens[r.Header.Get(headerAuthToken)]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	c := &call{w: w, r: r, user: s.users[userID]}
+	segs := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch segs[0] {
+	case "hello":
+		s.handleHello(c)
+	case "user":
+		s.handleUser(c)
+	case "users", "groups":
+		s.routeOwner(c, segs)
+	case "repos":
+		s.routeRepo(c, segs[1:])
+	case "doc_versions":
+		s.routeDocVersion(c, segs[1:])
+	case "search":
+		s.handleSearch(c)
+	default:
+		c.notFound()
+	}
+}
+
+// routeOwner dispatches users/{login}/... and groups/{login}/... requests.
+func (s *Server) routeOwner(c *call, segs []string) {
+	if len(segs) < 3 {
+		c.notFound()
+		return
+	}
+
+	switch {
+	case segs[0] == "users" && segs[2] == "groups" && len(segs) == 3:
+		s.handleUserGroups(c, segs[1])
+	case segs[0] == "groups" && segs[2] == "users" && len(segs) == 3:
+		s.handleGroupMembers(c, segs[1])
+	case segs[0] == "groups" && segs[2] == "users" && len(segs) == 4:
+		s.handleGroupMember(c, segs[1], segs[3])
+	case segs[0] == "groups" && segs[2] == "statistics":
+		s.routeStatistics(c, segs[1], segs[3:])
+	case segs[2] == "repos" && len(segs) == 3:
+		s.handleOwnerRepos(c, segs[0], segs[1])
+	default:
+		c.notFound()
+	}
+}
+
+// call is a request being served on behalf of an authenticated user.
+type call struct {
+	w    http.ResponseWriter
+	r    *http.Request
+	user *yuque.User
+}
+
+// decode decodes the JSON request body into v, reporting false after writing
+// an error response if the body is malformed.
+func (c *call) decode(v any) bool {
+	if err := json.NewDecoder(c.r.Body).Decode(v); err != nil {
+		writeError(c.w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// queryInt returns the integer query parameter, or def if absent or malformed.
+func (c *call) queryInt(name string, def int) int {
+	if v, err := strconv.Atoi(c.r.URL.Query().Get(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+// page returns the offset and limit query parameters, the limit capped at maxLimit.
+func (c *call) page(maxLimit int) (offset, limit int) {
+	offset = max(c.queryInt("offset", 0), 0)
+	limit = c.queryInt("limit", defaultLimit)
+	if limit <= 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+	return offset, limit
+}
+
+func (c *call) write(data any) {
+	writeJSON(c.w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (c *call) writeList(data any, total int) {
+	writeJSON(c.w, http.StatusOK, map[string]any{"data": data, "meta": map[string]int{"total": total}})
+}
+
+func (c *call) notFound() {
+	writeError(c.w, http.StatusNotFound, "Not Found")
+}
+
+func (c *call) forbidden() {
+	writeError(c.w, http.StatusForbidden, "Forbidden")
+}
+
+func (c *call) methodNotAllowed() {
+	writeError(c.w, http.StatusMethodNotAllowed, "Method Not Allowed")
+}
+
+// invalid writes a validation error envelope for a single field.
+func (c *call) invalid(field, code, message string) {
+	writeJSON(c.w, http.StatusBadRequest, map[string]any{
+		"status":  http.StatusBadRequest,
+		"message": "Validation Failed",
+		"errors":  []*yuque.FieldError{{Field: field, Code: code, Message: message}},
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"status": status, "message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// paginate returns the items of the page starting at offset.
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
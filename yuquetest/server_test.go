@@ -0,0 +1,322 @@
+package yuquetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/flc1125/go-yuque"
+)
+
+var ctx = context.Background()
+
+func newTestServer(t *testing.T) (*Server, *yuque.Client) {
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+
+	client, err := srv.NewClient(Token)
+	require.NoError(t, err)
+
+	return srv, client
+}
+
+// newTestRepo 创建默认用户的知识库
+func newTestRepo(t *testing.T, client *yuque.Client, slug string) *yuque.Book {
+	book, _, err := client.RepoService.CreateUserRepo(ctx, Login, &yuque.CreateRepoRequest{
+		Name: new("知识库 " + slug),
+		Slug: new(slug),
+	})
+	require.NoError(t, err)
+	return book
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	hello, resp, err := client.UserService.Hello(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello Yuque", hello.Message)
+	assert.NotEmpty(t, resp.RequestID())
+
+	user, _, err := client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Login, user.Login)
+
+	anonymous, err := srv.NewClient("invalid-token")
+	require.NoError(t, err)
+
+	_, _, err = anonymous.UserService.GetUser(ctx)
+	assert.ErrorIs(t, err, yuque.ErrUnauthorized)
+}
+
+func TestServer_RepoAndDoc(t *testing.T) {
+	_, client := newTestServer(t)
+
+	book := newTestRepo(t, client, "guide")
+	assert.Equal(t, "yuque/guide", book.Namespace)
+
+	got, _, err := client.RepoService.GetRepo(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, book.Name, got.Name)
+
+	created, _, err := client.DocService.CreateDoc(ctx, "yuque/guide", &yuque.CreateDocRequest{
+		Slug:  new("intro"),
+		Title: new("介绍"),
+		Body:  new("# 介绍"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, book.ID, created.BookID)
+	assert.Equal(t, yuque.DocFormatMarkdown, *created.Format)
+
+	updated, _, err := client.DocService.UpdateDoc(ctx, book.ID, "intro", &yuque.UpdateDocRequest{
+		Body: new("# 介绍\n\n正文"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "介绍", updated.Title)
+	assert.NotEqual(t, created.LatestVersionID, updated.LatestVersionID)
+
+	doc, _, err := client.DocService.GetDoc(ctx, "yuque/guide", created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "# 介绍\n\n正文", *doc.Body)
+	assert.Equal(t, book.ID, doc.Book.ID)
+
+	versions, _, err := client.DocService.ListDocVersions(ctx, doc.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, updated.LatestVersionID, versions[0].ID)
+	assert.Nil(t, versions[0].Body)
+
+	version, _, err := client.DocService.GetDocVersion(ctx, versions[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "# 介绍", *version.Body)
+
+	_, _, err = client.DocService.DeleteDoc(ctx, book.ID, doc.ID)
+	require.NoError(t, err)
+
+	_, _, err = client.DocService.GetDoc(ctx, book.ID, doc.ID)
+	assert.ErrorIs(t, err, yuque.ErrNotFound)
+
+	_, _, err = client.RepoService.DeleteRepo(ctx, book.ID)
+	require.NoError(t, err)
+
+	_, _, err = client.RepoService.GetRepo(ctx, "yuque/guide")
+	assert.ErrorIs(t, err, yuque.ErrNotFound)
+}
+
+func TestServer_Pagination(t *testing.T) {
+	_, client := newTestServer(t)
+
+	book := newTestRepo(t, client, "guide")
+	for range 5 {
+		_, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{})
+		require.NoError(t, err)
+	}
+
+	docs, _, err := client.DocService.GetDocs(ctx, book.ID, &yuque.GetDocsRequest{Offset: new(3), Limit: new(10)})
+	require.NoError(t, err)
+	assert.Equal(t, 5, docs.Total)
+	assert.Len(t, docs.Docs, 2)
+	assert.Nil(t, docs.Docs[0].Body)
+
+	var ids []int
+	for doc, err := range client.DocService.AllDocs(ctx, book.ID, &yuque.GetDocsRequest{Limit: new(2)}) {
+		require.NoError(t, err)
+		ids = append(ids, doc.ID)
+	}
+	assert.Len(t, ids, 5)
+	assert.IsIncreasing(t, ids)
+}
+
+func TestServer_Validation(t *testing.T) {
+	_, client := newTestServer(t)
+
+	newTestRepo(t, client, "guide")
+
+	_, _, err := client.RepoService.CreateUserRepo(ctx, Login, &yuque.CreateRepoRequest{
+		Name: new("重复"),
+		Slug: new("guide"),
+	})
+	require.ErrorIs(t, err, yuque.ErrValidation)
+
+	var errResp *yuque.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, []*yuque.FieldError{{Field: "slug", Code: "already_exists", Message: "slug already exists"}}, errResp.FieldErrors())
+}
+
+func TestServer_Groups(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	admin, _, err := client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+
+	reader := srv.AddUser("reader", "读者", "reader-token")
+	group := srv.AddGroup("team", "团队", admin.ID)
+
+	_, _, err = client.GroupService.UpdateGroupMember(ctx, "team", "reader", &yuque.UpdateGroupMemberRequest{
+		Role: new(yuque.GroupMemberRoleReadOnly),
+	})
+	require.NoError(t, err)
+
+	members, _, err := client.GroupService.GetGroupMembers(ctx, group.ID, nil)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, reader.ID, members[1].UserID)
+	assert.Equal(t, yuque.GroupMemberRoleReadOnly, members[1].Role)
+
+	groups, _, err := client.GroupService.GetUserGroups(ctx, "reader", nil)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "team", groups[0].Login)
+
+	_, _, err = client.RepoService.CreateGroupRepo(ctx, "team", &yuque.CreateRepoRequest{
+		Name: new("团队知识库"),
+		Slug: new("wiki"),
+	})
+	require.NoError(t, err)
+
+	readerClient, err := srv.NewClient("reader-token")
+	require.NoError(t, err)
+
+	repos, _, err := readerClient.RepoService.GetGroupRepos(ctx, "team", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repos.Total)
+
+	_, _, err = readerClient.DocService.CreateDoc(ctx, "team/wiki", &yuque.CreateDocRequest{})
+	assert.ErrorIs(t, err, yuque.ErrForbidden)
+
+	_, _, err = readerClient.RepoService.CreateUserRepo(ctx, Login, &yuque.CreateRepoRequest{
+		Name: new("他人知识库"),
+		Slug: new("other"),
+	})
+	assert.ErrorIs(t, err, yuque.ErrForbidden)
+
+	deleted, _, err := client.GroupService.DeleteGroupMember(ctx, "team", reader.ID)
+	require.NoError(t, err)
+	assert.Equal(t, reader.ID, deleted.UserID)
+
+	_, _, err = readerClient.RepoService.GetRepo(ctx, "team/wiki")
+	assert.ErrorIs(t, err, yuque.ErrForbidden)
+}
+
+func TestServer_TOC(t *testing.T) {
+	_, client := newTestServer(t)
+
+	book := newTestRepo(t, client, "guide")
+
+	var docIDs []int
+	for _, title := range []string{"安装", "配置", "常见问题"} {
+		doc, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{Title: new(title)})
+		require.NoError(t, err)
+		docIDs = append(docIDs, doc.ID)
+	}
+
+	desired := []*yuque.DesiredTOCNode{
+		{Title: "快速开始", Children: []*yuque.DesiredTOCNode{
+			{DocID: docIDs[0]},
+			{DocID: docIDs[1]},
+		}},
+		{DocID: docIDs[2]},
+		{Title: "官网", URL: "https://www.yuque.com"},
+	}
+
+	plan, _, err := client.DocService.ReconcileTOC(ctx, book.ID, &yuque.ReconcileTOCRequest{Desired: desired})
+	require.NoError(t, err)
+	assert.Len(t, plan.Operations, 5)
+
+	tocs, _, err := client.DocService.GetTOCs(ctx, "yuque/guide")
+	require.NoError(t, err)
+
+	tree := yuque.NewTOCTree(tocs)
+	require.NoError(t, tree.Validate())
+	assert.Equal(t, 5, tree.Len())
+
+	node, ok := tree.NodeByPath("快速开始/配置")
+	require.True(t, ok)
+	assert.Equal(t, docIDs[1], node.DocID)
+	assert.Equal(t, 1, node.Level)
+
+	// reconciling again is a no-op
+	plan, _, err = client.DocService.ReconcileTOC(ctx, book.ID, &yuque.ReconcileTOCRequest{Desired: desired})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+
+	// deleting a doc removes it from the TOC
+	_, _, err = client.DocService.DeleteDoc(ctx, book.ID, docIDs[0])
+	require.NoError(t, err)
+
+	tocs, _, err = client.DocService.GetTOCs(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Len(t, tocs, 4)
+
+	_, _, err = client.DocService.UpdateTOC(ctx, book.ID, &yuque.UpdateTOCRequest{
+		Action:   new(yuque.TOCActionRemoveNode),
+		NodeUUID: new("missing"),
+	})
+	assert.ErrorIs(t, err, yuque.ErrValidation)
+}
+
+func TestServer_Search(t *testing.T) {
+	_, client := newTestServer(t)
+
+	book := newTestRepo(t, client, "guide")
+	_, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{
+		Title: new("会议室演示"),
+		Body:  new("在使用会议室的电脑进行展示时"),
+	})
+	require.NoError(t, err)
+
+	results, _, err := client.SearchService.Search(ctx, &yuque.SearchRequest{
+		Q:    new("会议室"),
+		Type: new(yuque.SearchTypeDoc),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, results.Total)
+	assert.Equal(t, "<em>会议室</em>演示", results.Results[0].Title)
+	assert.Equal(t, []string{"会议室", "会议室"}, results.Results[0].Highlights())
+	assert.Equal(t, book.ID, results.Results[0].Doc.BookID)
+
+	results, _, err = client.SearchService.Search(ctx, &yuque.SearchRequest{
+		Q:    new("guide"),
+		Type: new(yuque.SearchTypeRepo),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, results.Total)
+	assert.Equal(t, book.ID, results.Results[0].Book.ID)
+}
+
+func TestServer_Statistics(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	user, _, err := client.UserService.GetUser(ctx)
+	require.NoError(t, err)
+	srv.AddGroup("team", "团队", user.ID)
+
+	book, _, err := client.RepoService.CreateGroupRepo(ctx, "team", &yuque.CreateRepoRequest{Name: new("知识库"), Slug: new("wiki")})
+	require.NoError(t, err)
+	for _, body := range []string{"短", "较长的正文"} {
+		_, _, err := client.DocService.CreateDoc(ctx, book.ID, &yuque.CreateDocRequest{Body: new(body)})
+		require.NoError(t, err)
+	}
+
+	summary, _, err := client.StatisticService.GetGroupStatistics(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.MemberCount)
+	assert.Equal(t, 1, summary.BookCount)
+	assert.Equal(t, 2, summary.DocCount)
+
+	docs, _, err := client.StatisticService.GetDocStatistics(ctx, "team", &yuque.GetDocStatisticsRequest{
+		SortField: new(yuque.DocStatisticSortFieldWordCount),
+		Limit:     new(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, docs.Total)
+	require.Len(t, docs.Docs, 1)
+	assert.Equal(t, 5, docs.Docs[0].WordCount)
+
+	members, _, err := client.StatisticService.GetMemberStatistics(ctx, "team", nil)
+	require.NoError(t, err)
+	require.Len(t, members.Members, 1)
+	assert.Equal(t, 2, members.Members[0].WriteDocCount)
+}
@@ -0,0 +1,236 @@
+package yuquetest
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/flc1125/go-yuque"
+)
+
+const (
+	// statisticsMaxLimit is the maximum page size of the statistics endpoints.
+	statisticsMaxLimit = 20
+
+	// statisticsDefaultLimit is the page size of the statistics endpoints when unspecified.
+	statisticsDefaultLimit = 10
+)
+
+// routeStatistics dispatches groups/{login}/statistics/... requests.
+//
+// The statistics are derived from the store: counts of members, repos, docs
+// and versions, while reads, likes and comments are always zero.
+func (s *Server) routeStatistics(c *call, login string, segs []string) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+
+	group := s.lookupGroup(login)
+	if group == nil {
+		c.notFound()
+		return
+	}
+	if s.member(group.ID, c.user.ID) == nil {
+		c.forbidden()
+		return
+	}
+
+	books := s.sortedBooks(func(book *yuque.Book) bool { return book.UserID == group.ID })
+	bizDate := s.now().Format("20060102")
+
+	switch {
+	case len(segs) == 0:
+		s.handleGroupStatistics(c, group, books, bizDate)
+	case len(segs) == 1 && segs[0] == "members":
+		s.handleMemberStatistics(c, group, books, bizDate)
+	case len(segs) == 1 && segs[0] == "books":
+		s.handleBookStatistics(c, books, bizDate)
+	case len(segs) == 1 && segs[0] == "docs":
+		s.handleDocStatistics(c, books, bizDate)
+	default:
+		c.notFound()
+	}
+}
+
+func (s *Server) handleGroupStatistics(c *call, group *yuque.Group, books []*yuque.Book, bizDate string) {
+	stats := &yuque.GetGroupStatisticsResponse{
+		BizDate:     bizDate,
+		UserID:      group.ID,
+		MemberCount: group.MembersCount,
+		BookCount:   len(books),
+	}
+
+	for _, book := range books {
+		if book.Public == yuque.AccessTypePrivate {
+			stats.PrivateBookCount++
+		} else {
+			stats.PublicBookCount++
+		}
+		if book.Type == yuque.BookTypeBook {
+			stats.BookBookCount++
+		}
+
+		for _, doc := range s.sortedDocs(book.ID) {
+			stats.DocCount++
+			stats.WriteCount += s.versionCount(doc.ID, 0)
+		}
+	}
+	stats.ContentCount = stats.DocCount
+
+	c.write(stats)
+}
+
+// versionCount returns the number of versions of the doc, written by the user unless userID is 0.
+func (s *Server) versionCount(docID, userID int) int {
+	count := 0
+	for _, v := range s.versions {
+		if v.DocID == docID && (userID == 0 || v.UserID == userID) {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) handleMemberStatistics(c *call, group *yuque.Group, books []*yuque.Book, bizDate string) {
+	name := c.r.URL.Query().Get("name")
+
+	var members []*yuque.MemberStatistic
+	for _, m := range s.members {
+		if m.GroupID != group.ID || !strings.Contains(m.User.Name, name) {
+			continue
+		}
+
+		stat := &yuque.MemberStatistic{
+			UserID:  m.UserID,
+			GroupID: group.ID,
+			BizDate: bizDate,
+			User:    m.User,
+		}
+		for _, book := range books {
+			for _, doc := range s.sortedDocs(book.ID) {
+				if doc.UserID == m.UserID {
+					stat.WriteDocCount++
+				}
+				stat.WriteCount += s.versionCount(doc.ID, m.UserID)
+			}
+		}
+		members = append(members, stat)
+	}
+
+	sortStatistics(c, members, map[string]func(*yuque.MemberStatistic) int64{
+		string(yuque.MemberStatisticSortFieldWriteDocCount): func(m *yuque.MemberStatistic) int64 { return int64(m.WriteDocCount) },
+		string(yuque.MemberStatisticSortFieldWriteCount):    func(m *yuque.MemberStatistic) int64 { return int64(m.WriteCount) },
+	})
+
+	c.write(&yuque.GetMemberStatisticsResponse{Total: len(members), Members: pageStatistics(c, members)})
+}
+
+func (s *Server) handleBookStatistics(c *call, books []*yuque.Book, bizDate string) {
+	name := c.r.URL.Query().Get("name")
+
+	var stats []*yuque.BookStatistic
+	for _, book := range books {
+		if !strings.Contains(book.Name, name) {
+			continue
+		}
+
+		stat := &yuque.BookStatistic{
+			BookID:             book.ID,
+			BizDate:            bizDate,
+			UserID:             book.UserID,
+			Slug:               book.Slug,
+			Name:               book.Name,
+			Type:               book.Type,
+			Public:             book.Public,
+			ContentUpdatedAt:   book.ContentUpdatedAt,
+			ContentUpdatedAtMs: book.ContentUpdatedAt.UnixMilli(),
+			CreatedAt:          book.CreatedAt,
+			UpdatedAt:          book.UpdatedAt,
+			User:               book.User,
+		}
+		for _, doc := range s.sortedDocs(book.ID) {
+			stat.PostCount++
+			stat.WordCount += doc.WordCount
+		}
+		stats = append(stats, stat)
+	}
+
+	sortStatistics(c, stats, map[string]func(*yuque.BookStatistic) int64{
+		string(yuque.BookStatisticSortFieldContentUpdatedAt): func(b *yuque.BookStatistic) int64 { return b.ContentUpdatedAtMs },
+		string(yuque.BookStatisticSortFieldWordCount):        func(b *yuque.BookStatistic) int64 { return int64(b.WordCount) },
+		string(yuque.BookStatisticSortFieldPostCount):        func(b *yuque.BookStatistic) int64 { return int64(b.PostCount) },
+	})
+
+	c.write(&yuque.GetBookStatisticsResponse{Total: len(stats), Books: pageStatistics(c, stats)})
+}
+
+func (s *Server) handleDocStatistics(c *call, books []*yuque.Book, bizDate string) {
+	name := c.r.URL.Query().Get("name")
+	bookID := c.queryInt("bookId", 0)
+
+	var stats []*yuque.DocStatistic
+	for _, book := range books {
+		if bookID != 0 && book.ID != bookID {
+			continue
+		}
+
+		for _, doc := range s.sortedDocs(book.ID) {
+			if !strings.Contains(doc.Title, name) {
+				continue
+			}
+
+			stats = append(stats, &yuque.DocStatistic{
+				DocID:            doc.ID,
+				BizDate:          bizDate,
+				BookID:           book.ID,
+				UserID:           doc.UserID,
+				Slug:             doc.Slug,
+				Title:            doc.Title,
+				WordCount:        doc.WordCount,
+				ContentUpdatedAt: doc.ContentUpdatedAt,
+				CreatedAt:        doc.CreatedAt,
+				UpdatedAt:        doc.UpdatedAt,
+				User:             doc.User,
+				Book:             book,
+			})
+		}
+	}
+
+	sortStatistics(c, stats, map[string]func(*yuque.DocStatistic) int64{
+		string(yuque.DocStatisticSortFieldContentUpdatedAt): func(d *yuque.DocStatistic) int64 { return d.ContentUpdatedAt.UnixMilli() },
+		string(yuque.DocStatisticSortFieldCreatedAt):        func(d *yuque.DocStatistic) int64 { return d.CreatedAt.UnixMilli() },
+		string(yuque.DocStatisticSortFieldWordCount):        func(d *yuque.DocStatistic) int64 { return int64(d.WordCount) },
+	})
+
+	c.write(&yuque.GetDocStatisticsResponse{Total: len(stats), Docs: pageStatistics(c, stats)})
+}
+
+// sortStatistics sorts the items by the sortField and sortOrder query parameters,
+// descending by default. Unknown fields, such as counts that are always zero, keep the order.
+func sortStatistics[T any](c *call, items []T, keys map[string]func(T) int64) {
+	key, ok := keys[c.r.URL.Query().Get("sortField")]
+	if !ok {
+		return
+	}
+
+	desc := c.r.URL.Query().Get("sortOrder") != string(yuque.StatisticSortOrderAsc)
+	slices.SortStableFunc(items, func(a, b T) int {
+		if desc {
+			return cmp.Compare(key(b), key(a))
+		}
+		return cmp.Compare(key(a), key(b))
+	})
+}
+
+// pageStatistics returns the page of items selected by the page and limit query parameters.
+func pageStatistics[T any](c *call, items []T) []T {
+	limit := c.queryInt("limit", statisticsDefaultLimit)
+	if limit <= 0 || limit > statisticsMaxLimit {
+		limit = statisticsMaxLimit
+	}
+	page := max(c.queryInt("page", 1), 1)
+
+	return paginate(items, (page-1)*limit, limit)
+}
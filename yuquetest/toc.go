@@ -0,0 +1,296 @@
+package yuquetest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/flc1125/go-yuque"
+)
+
+// tocNode is a node of the TOC of a repo, the root node holds no TOC item.
+//
+// The title of a DOC node is empty unless edited, in which case the title of
+// the doc is shown instead.
+type tocNode struct {
+	toc      yuque.TOC
+	parent   *tocNode
+	children []*tocNode
+}
+
+// find returns the node with the UUID in the subtree of n, nil if absent.
+func (n *tocNode) find(uuid string) *tocNode {
+	if n.toc.UUID == uuid && n.parent != nil {
+		return n
+	}
+	for _, child := range n.children {
+		if found := child.find(uuid); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// contains reports whether other is n or one of its descendants.
+func (n *tocNode) contains(other *tocNode) bool {
+	for ; other != nil; other = other.parent {
+		if other == n {
+			return true
+		}
+	}
+	return false
+}
+
+// index returns the position of n among its siblings.
+func (n *tocNode) index() int {
+	return slices.Index(n.parent.children, n)
+}
+
+// detach removes n along with its subtree from its parent.
+func (n *tocNode) detach() {
+	n.parent.children = slices.Delete(n.parent.children, n.index(), n.index()+1)
+	n.parent = nil
+}
+
+// insert inserts the node as the i-th child of n.
+func (n *tocNode) insert(i int, child *tocNode) {
+	child.parent = n
+	n.children = slices.Insert(n.children, i, child)
+}
+
+// remove removes n from its parent, lifting its children into its place unless withChildren is set.
+func (n *tocNode) remove(withChildren bool) {
+	parent, i := n.parent, n.index()
+	n.detach()
+
+	if withChildren {
+		return
+	}
+	for j, child := range n.children {
+		parent.insert(i+j, child)
+	}
+	n.children = nil
+}
+
+// removeDoc removes the nodes of the doc in the subtree of n, lifting their children.
+func (n *tocNode) removeDoc(docID int) {
+	for _, child := range slices.Clone(n.children) {
+		child.removeDoc(docID)
+		if child.toc.Type == yuque.TOCTypeDoc && child.toc.DocID == docID {
+			child.remove(false)
+		}
+	}
+}
+
+// flattenTOC returns the TOC items below root in pre-order, as returned by GetTOCs.
+func (s *Server) flattenTOC(root *tocNode) []*yuque.TOC {
+	tocs := []*yuque.TOC{}
+
+	var walk func(n *tocNode, level int)
+	walk = func(n *tocNode, level int) {
+		for i, child := range n.children {
+			toc := child.toc
+			toc.Level, toc.Depth = level, level+1
+			toc.PrevUUID, toc.SiblingUUID, toc.ChildUUID = "", "", ""
+			toc.ParentUUID = n.toc.UUID
+
+			if i > 0 {
+				toc.PrevUUID = n.children[i-1].toc.UUID
+			}
+			if i+1 < len(n.children) {
+				toc.SiblingUUID = n.children[i+1].toc.UUID
+			}
+			if len(child.children) > 0 {
+				toc.ChildUUID = child.children[0].toc.UUID
+			}
+
+			if toc.Type == yuque.TOCTypeDoc {
+				toc.ID = toc.DocID
+				if doc, ok := s.docs[toc.DocID]; ok {
+					toc.URL, toc.Slug = doc.Slug, doc.Slug
+					if toc.Title == "" {
+						toc.Title = doc.Title
+					}
+				}
+			}
+
+			tocs = append(tocs, &toc)
+			walk(child, level+1)
+		}
+	}
+	walk(root, 0)
+
+	return tocs
+}
+
+func (s *Server) handleTOC(c *call, book *yuque.Book) {
+	root := s.tocs[book.ID]
+
+	switch c.r.Method {
+	case http.MethodGet:
+		c.write(s.flattenTOC(root))
+
+	case http.MethodPut:
+		if !s.canWrite(book, c.user.ID) {
+			c.forbidden()
+			return
+		}
+
+		var req yuque.UpdateTOCRequest
+		if !c.decode(&req) {
+			return
+		}
+		if req.Action == nil {
+			c.invalid("action", "missing_field", "action is required")
+			return
+		}
+
+		if field, message := s.updateTOC(book, root, &req); field != "" {
+			c.invalid(field, "invalid", message)
+			return
+		}
+
+		book.ContentUpdatedAt = s.now()
+		c.write(s.flattenTOC(root))
+
+	default:
+		c.methodNotAllowed()
+	}
+}
+
+// updateTOC applies the action to the TOC, returning the offending field and a message if invalid.
+func (s *Server) updateTOC(book *yuque.Book, root *tocNode, req *yuque.UpdateTOCRequest) (field, message string) {
+	var node *tocNode
+	if req.NodeUUID != nil && *req.NodeUUID != "" {
+		if node = root.find(*req.NodeUUID); node == nil {
+			return "node_uuid", fmt.Sprintf("node %s not found", *req.NodeUUID)
+		}
+	}
+
+	mode := yuque.TOCActionModeSibling
+	if req.ActionMode != nil {
+		mode = *req.ActionMode
+	}
+
+	switch *req.Action {
+	case yuque.TOCActionEditNode:
+		if node == nil {
+			return "node_uuid", "node_uuid is required"
+		}
+		if req.Title != nil {
+			node.toc.Title = *req.Title
+		}
+		if req.URL != nil {
+			node.toc.URL = *req.URL
+		}
+		if req.OpenWindow != nil {
+			node.toc.OpenWindow = *req.OpenWindow
+		}
+		if req.Visible != nil {
+			node.toc.Visible = *req.Visible
+		}
+
+	case yuque.TOCActionRemoveNode:
+		if node == nil {
+			return "node_uuid", "node_uuid is required"
+		}
+		node.remove(mode == yuque.TOCActionModeChild)
+
+	case yuque.TOCActionAppendNode, yuque.TOCActionPrependNode:
+		target := root
+		if req.TargetUUID != nil && *req.TargetUUID != "" {
+			if target = root.find(*req.TargetUUID); target == nil {
+				return "target_uuid", fmt.Sprintf("node %s not found", *req.TargetUUID)
+			}
+		} else {
+			mode = yuque.TOCActionModeChild
+		}
+
+		nodes := []*tocNode{node}
+		if node == nil {
+			if nodes, field, message = s.newTOCNodes(book, req); field != "" {
+				return field, message
+			}
+		} else {
+			if node.contains(target) {
+				return "target_uuid", "cannot move a node into itself"
+			}
+			node.detach()
+		}
+
+		parent, i := target, 0
+		if mode == yuque.TOCActionModeChild {
+			if *req.Action == yuque.TOCActionAppendNode {
+				i = len(target.children)
+			}
+		} else {
+			parent, i = target.parent, target.index()
+			if *req.Action == yuque.TOCActionAppendNode {
+				i++
+			}
+		}
+
+		for j, n := range nodes {
+			parent.insert(i+j, n)
+		}
+
+	default:
+		return "action", fmt.Sprintf("unknown action %s", *req.Action)
+	}
+
+	return "", ""
+}
+
+// newTOCNodes builds the nodes to insert, one for each doc of a DOC node.
+// The offending field and a message are returned if the request is invalid.
+func (s *Server) newTOCNodes(book *yuque.Book, req *yuque.UpdateTOCRequest) (nodes []*tocNode, field, message string) {
+	if req.Type == nil {
+		return nil, "type", "type is required"
+	}
+
+	newNode := func() *tocNode {
+		n := &tocNode{toc: yuque.TOC{UUID: fmt.Sprintf("toc%d", s.nextID()), Type: *req.Type, Visible: 1}}
+		if req.OpenWindow != nil {
+			n.toc.OpenWindow = *req.OpenWindow
+		}
+		if req.Visible != nil {
+			n.toc.Visible = *req.Visible
+		}
+		return n
+	}
+
+	switch *req.Type {
+	case yuque.TOCTypeDoc:
+		if len(req.DocIDs) == 0 {
+			return nil, "doc_ids", "doc_ids is required"
+		}
+		for _, id := range req.DocIDs {
+			if doc, exists := s.docs[id]; !exists || doc.BookID != book.ID {
+				return nil, "doc_ids", fmt.Sprintf("doc %d not found", id)
+			}
+			n := newNode()
+			n.toc.DocID = id
+			nodes = append(nodes, n)
+		}
+
+	case yuque.TOCTypeLink, yuque.TOCTypeTitle:
+		if req.Title == nil || *req.Title == "" {
+			return nil, "title", "title is required"
+		}
+		if *req.Type == yuque.TOCTypeLink && (req.URL == nil || *req.URL == "") {
+			return nil, "url", "url is required"
+		}
+
+		n := newNode()
+		n.toc.Title = *req.Title
+		if req.URL != nil {
+			n.toc.URL = *req.URL
+		}
+		nodes = append(nodes, n)
+
+	default:
+		return nil, "type", fmt.Sprintf("unknown type %s", *req.Type)
+	}
+
+	return nodes, "", ""
+}
@@ -0,0 +1,191 @@
+package yuquetest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/flc1125/go-yuque"
+)
+
+func (s *Server) handleHello(c *call) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+	c.write(&yuque.HelloResponse{Message: "Hello " + c.user.Name})
+}
+
+func (s *Server) handleUser(c *call) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+	c.write(c.user)
+}
+
+// lookupUser returns the user by ID or login.
+func (s *Server) lookupUser(login string) *yuque.User {
+	if id, err := strconv.Atoi(login); err == nil {
+		return s.users[id]
+	}
+	for _, user := range s.users {
+		if user.Login == login {
+			return user
+		}
+	}
+	return nil
+}
+
+// lookupGroup returns the group by ID or login.
+func (s *Server) lookupGroup(login string) *yuque.Group {
+	if id, err := strconv.Atoi(login); err == nil {
+		return s.groups[id]
+	}
+	for _, group := range s.groups {
+		if group.Login == login {
+			return group
+		}
+	}
+	return nil
+}
+
+// member returns the membership of the user in the group, nil if not a member.
+func (s *Server) member(groupID, userID int) *yuque.GroupMember {
+	for _, m := range s.members {
+		if m.GroupID == groupID && m.UserID == userID {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *Server) setMember(group *yuque.Group, userID int, role yuque.GroupMemberRole) *yuque.GroupMember {
+	user, ok := s.users[userID]
+	if !ok {
+		return nil
+	}
+
+	now := s.now()
+	if m := s.member(group.ID, userID); m != nil {
+		m.Role = role
+		m.UpdatedAt = now
+		return m
+	}
+
+	m := &yuque.GroupMember{
+		ID:        s.nextID(),
+		GroupID:   group.ID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Group:     group,
+		User:      user,
+	}
+	s.members = append(s.members, m)
+	group.MembersCount++
+
+	return m
+}
+
+// isAdmin reports whether the user administers the group.
+func (s *Server) isAdmin(groupID, userID int) bool {
+	m := s.member(groupID, userID)
+	return m != nil && m.Role == yuque.GroupMemberRoleAdmin
+}
+
+// membersOf returns the memberships matching match in the order they were added,
+// filtered by the role query parameter.
+func (s *Server) membersOf(c *call, match func(*yuque.GroupMember) bool) []*yuque.GroupMember {
+	role := c.queryInt("role", -1)
+
+	var members []*yuque.GroupMember
+	for _, m := range s.members {
+		if match(m) && (role < 0 || int(m.Role) == role) {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+func (s *Server) handleUserGroups(c *call, login string) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+
+	user := s.lookupUser(login)
+	if user == nil {
+		c.notFound()
+		return
+	}
+
+	var groups []*yuque.Group
+	for _, m := range s.membersOf(c, func(m *yuque.GroupMember) bool { return m.UserID == user.ID }) {
+		groups = append(groups, s.groups[m.GroupID])
+	}
+
+	offset, limit := c.page(defaultLimit)
+	c.write(paginate(groups, offset, limit))
+}
+
+func (s *Server) handleGroupMembers(c *call, login string) {
+	if c.r.Method != http.MethodGet {
+		c.methodNotAllowed()
+		return
+	}
+
+	group := s.lookupGroup(login)
+	if group == nil {
+		c.notFound()
+		return
+	}
+
+	members := s.membersOf(c, func(m *yuque.GroupMember) bool { return m.GroupID == group.ID })
+
+	offset, limit := c.page(defaultLimit)
+	c.write(paginate(members, offset, limit))
+}
+
+func (s *Server) handleGroupMember(c *call, login, userLogin string) {
+	group := s.lookupGroup(login)
+	user := s.lookupUser(userLogin)
+	if group == nil || user == nil {
+		c.notFound()
+		return
+	}
+
+	if !s.isAdmin(group.ID, c.user.ID) {
+		c.forbidden()
+		return
+	}
+
+	switch c.r.Method {
+	case http.MethodPut:
+		var req yuque.UpdateGroupMemberRequest
+		if !c.decode(&req) {
+			return
+		}
+		if req.Role == nil {
+			c.invalid("role", "missing_field", "role is required")
+			return
+		}
+		c.write(s.setMember(group, user.ID, *req.Role))
+
+	case http.MethodDelete:
+		i := slices.IndexFunc(s.members, func(m *yuque.GroupMember) bool {
+			return m.GroupID == group.ID && m.UserID == user.ID
+		})
+		if i < 0 {
+			c.notFound()
+			return
+		}
+		s.members = slices.Delete(s.members, i, i+1)
+		group.MembersCount--
+		c.write(&yuque.DeleteGroupMemberResponse{UserID: user.ID})
+
+	default:
+		c.methodNotAllowed()
+	}
+}